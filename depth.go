@@ -0,0 +1,257 @@
+package uniswap_core
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// LiquidityBucket is the range of ticks [TickLower, TickUpper) with the same active liquidity
+type LiquidityBucket struct {
+	TickLower *big.Int
+	TickUpper *big.Int
+	// the sqrt prices of the boundaries of the range
+	SqrtPriceLowerX96 *big.Int
+	SqrtPriceUpperX96 *big.Int
+	// the liquidity which is active while the current tick is in the range
+	Liquidity *big.Int
+	// the amounts of token0 and token1 locked in the range at the current price, rounded down
+	Amount0 *big.Int
+	Amount1 *big.Int
+}
+
+// LiquidityDistribution returns the active liquidity in the window [tickLower, tickUpper] of ticks,
+// split at the initialized ticks, ordered by tick. The liquidity is summed up from the current liquidity
+// of the pool outward, i.e. the liquidityNet of the ticks above the current tick is added and the one
+// of the ticks below is subtracted like the swap does crossing the ticks
+// ticker TickReader	tick bitmap object
+// slotReader PoolStateReader	Pool's state retriever object
+// tickLower	big.Int	The lower tick of the window
+// tickUpper	big.Int	The upper tick of the window
+// Returns ErrTickRangeInvalid if the window is empty or out of [MIN_TICK, MAX_TICK]
+func LiquidityDistribution(
+	ticker TickReader,
+	slotReader PoolStateReader,
+	tickLower *big.Int,
+	tickUpper *big.Int) ([]LiquidityBucket, error) {
+	if tickLower.Cmp(tickUpper) >= 0 || tickLower.Cmp(MIN_TICK) < 0 || tickUpper.Cmp(MAX_TICK) > 0 {
+		return nil, fmt.Errorf("%w: LiquidityDistribution: window [%d, %d] must be in [%d, %d]",
+			ErrTickRangeInvalid, tickLower, tickUpper, MIN_TICK, MAX_TICK)
+	}
+
+	slot0, err := slotReader.CurrentState()
+	if err != nil {
+		return nil, err
+	}
+
+	below := make([]LiquidityBucket, 0)
+	above := make([]LiquidityBucket, 0)
+
+	// the ranges are clipped by the window, the empty ones are skipped
+	appendBucket := func(buckets []LiquidityBucket, lower *big.Int, upper *big.Int, liquidity *big.Int) []LiquidityBucket {
+		if lower.Cmp(tickLower) < 0 {
+			lower = tickLower
+		}
+		if upper.Cmp(tickUpper) > 0 {
+			upper = tickUpper
+		}
+		if lower.Cmp(upper) >= 0 {
+			return buckets
+		}
+
+		return append(buckets, LiquidityBucket{
+			TickLower: big.NewInt(0).Set(lower),
+			TickUpper: big.NewInt(0).Set(upper),
+			Liquidity: big.NewInt(0).Set(liquidity)})
+	}
+
+	// walk to the right, the liquidityNet is added when the tick is crossed from left to right
+	liquidity := slot0.Liquidity
+	lower, tick := slot0.TickCurrent, slot0.TickCurrent
+
+	for lower.Cmp(tickUpper) < 0 {
+		next, initialized := ticker.NextInitializedTick(tick, false)
+		if next.Cmp(MAX_TICK) > 0 {
+			next = MAX_TICK
+		}
+
+		if next.Cmp(tickUpper) >= 0 {
+			above = appendBucket(above, lower, tickUpper, liquidity)
+			break
+		}
+
+		if initialized {
+			above = appendBucket(above, lower, next, liquidity)
+
+			var err error
+			if liquidity, err = TryAddLiquidityDelta(liquidity, ticker.GetLiquidityNet(next), false); err != nil {
+				return nil, err
+			}
+			lower = next
+		}
+		tick = next
+	}
+
+	// walk to the left, the liquidityNet is subtracted when the tick is crossed from right to left
+	liquidity = slot0.Liquidity
+	upper, tick := slot0.TickCurrent, slot0.TickCurrent
+
+	for upper.Cmp(tickLower) > 0 {
+		next, initialized := ticker.NextInitializedTick(tick, true)
+		if next.Cmp(MIN_TICK) < 0 {
+			next = MIN_TICK
+		}
+
+		if next.Cmp(tickLower) <= 0 {
+			below = appendBucket(below, tickLower, upper, liquidity)
+			break
+		}
+
+		if initialized {
+			below = appendBucket(below, next, upper, liquidity)
+
+			var err error
+			liquidityNet := big.NewInt(0).Neg(ticker.GetLiquidityNet(next))
+			if liquidity, err = TryAddLiquidityDelta(liquidity, liquidityNet, false); err != nil {
+				return nil, err
+			}
+			upper = next
+		}
+		tick = big.NewInt(0).Sub(next, ONE_UINT_256)
+	}
+
+	buckets := make([]LiquidityBucket, 0, len(below)+len(above))
+	for i := len(below) - 1; i >= 0; i-- {
+		buckets = append(buckets, below[i])
+	}
+
+	// the range of the current tick is split by the walks
+	for _, bucket := range above {
+		if n := len(buckets); n > 0 && buckets[n-1].TickUpper.Cmp(bucket.TickLower) == 0 &&
+			bucket.TickLower.Cmp(slot0.TickCurrent) == 0 && buckets[n-1].Liquidity.Cmp(bucket.Liquidity) == 0 {
+			buckets[n-1].TickUpper = bucket.TickUpper
+			continue
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	for i := range buckets {
+		if err := buckets[i].setAmounts(slot0.SqrtPriceX96); err != nil {
+			return nil, err
+		}
+	}
+
+	return buckets, nil
+}
+
+// setAmounts computes the prices of the boundaries and the amounts locked in the range at the price
+func (b *LiquidityBucket) setAmounts(sqrtPriceX96 *big.Int) (err error) {
+	if b.SqrtPriceLowerX96, err = TryGetSqrtRatioAtTick(b.TickLower); err != nil {
+		return
+	}
+	if b.SqrtPriceUpperX96, err = TryGetSqrtRatioAtTick(b.TickUpper); err != nil {
+		return
+	}
+
+	b.Amount0, b.Amount1 = big.NewInt(0), big.NewInt(0)
+
+	// the range below the price holds token1 only, the range above the price token0 only
+	sqrtPriceLowerX96, sqrtPriceUpperX96 := b.SqrtPriceLowerX96, b.SqrtPriceUpperX96
+	if sqrtPriceX96.Cmp(sqrtPriceLowerX96) > 0 {
+		sqrtPriceLowerX96 = sqrtPriceX96
+	}
+	if sqrtPriceX96.Cmp(sqrtPriceUpperX96) < 0 {
+		sqrtPriceUpperX96 = sqrtPriceX96
+	}
+
+	if sqrtPriceLowerX96.Cmp(b.SqrtPriceUpperX96) < 0 {
+		if b.Amount0, err = TryGetAmount0DeltaRoundingUp(sqrtPriceLowerX96, b.SqrtPriceUpperX96, b.Liquidity, false, false); err != nil {
+			return
+		}
+	}
+
+	if b.SqrtPriceLowerX96.Cmp(sqrtPriceUpperX96) < 0 {
+		if b.Amount1, err = TryGetAmount1DeltaRoundingUp(b.SqrtPriceLowerX96, sqrtPriceUpperX96, b.Liquidity, false, false); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// the denominator of the basis points, 1 bps = 0.01%
+var bpsDenominator = big.NewInt(10000)
+
+// DepthAtPriceImpact returns the amounts of the swap which moves the price by the given basis points,
+// i.e. the swap of DoSwap with the exact input which is stopped by the sqrt price limit of the price
+// P * (1 - bps / 10000) if zero for one, or P * (1 + bps / 10000) otherwise. The limit is rounded towards
+// the current price and is kept in (MIN_SQRT_RATIO, MAX_SQRT_RATIO)
+// pool PoolStateReader	Pool's state retriever object
+// ticks TickReader	tick bitmap object
+// zeroForOne	bool	The direction of the swap, true for token0 to token1, false for token1 to token0
+// bps	big.Int	The price impact in basis points, in (0, 10000) if zero for one or positive otherwise
+// amount0	big.Int	The delta of the balance of token0 of the pool, the input includes the fee
+// amount1	big.Int	The delta of the balance of token1 of the pool, the input includes the fee
+// Returns ErrPriceLimitInvalid if the bps are out of range and the errors of DoSwap
+func DepthAtPriceImpact(
+	pool PoolStateReader,
+	ticks TickReader,
+	zeroForOne bool,
+	bps *big.Int) (amount0 *big.Int, amount1 *big.Int, err error) {
+	slot0, err := pool.CurrentState()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sqrtPriceLimitX96, err := sqrtPriceAtImpact(slot0.SqrtPriceX96, zeroForOne, bps)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the amount is never reached, the swap stops at the limit
+	result, err := doSwap(zeroForOne, MAX_INT_256, sqrtPriceLimitX96, ticks, slot0, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result.Amount0, result.Amount1, nil
+}
+
+// sqrtPriceAtImpact returns sqrt(P * (10000 -+ bps) / 10000) rounded towards the sqrt price sqrt(P)
+func sqrtPriceAtImpact(sqrtPriceX96 *big.Int, zeroForOne bool, bps *big.Int) (*big.Int, error) {
+	if bps.Sign() <= 0 || zeroForOne && bps.Cmp(bpsDenominator) >= 0 {
+		return nil, fmt.Errorf("%w: price impact %d bps must be in (0, %d) if zero for one or positive otherwise",
+			ErrPriceLimitInvalid, bps, bpsDenominator)
+	}
+
+	factor := big.NewInt(0).Add(bpsDenominator, bps)
+	if zeroForOne {
+		factor.Sub(bpsDenominator, bps)
+	}
+
+	priceX192 := big.NewInt(0).Mul(sqrtPriceX96, sqrtPriceX96)
+	priceX192.Mul(priceX192, factor)
+
+	if zeroForOne {
+		// the ceiling of the root
+		priceX192.Add(priceX192, big.NewInt(0).Sub(bpsDenominator, ONE_UINT_256))
+		priceX192.Div(priceX192, bpsDenominator)
+
+		sqrtPriceLimitX96 := big.NewInt(0).Sqrt(priceX192)
+		if big.NewInt(0).Mul(sqrtPriceLimitX96, sqrtPriceLimitX96).Cmp(priceX192) < 0 {
+			sqrtPriceLimitX96.Add(sqrtPriceLimitX96, ONE_UINT_256)
+		}
+
+		if sqrtPriceLimitX96.Cmp(MIN_SQRT_RATIO) <= 0 {
+			sqrtPriceLimitX96.Add(MIN_SQRT_RATIO, ONE_UINT_256)
+		}
+		return sqrtPriceLimitX96, nil
+	}
+
+	priceX192.Div(priceX192, bpsDenominator)
+
+	sqrtPriceLimitX96 := big.NewInt(0).Sqrt(priceX192)
+	if sqrtPriceLimitX96.Cmp(MAX_SQRT_RATIO) >= 0 {
+		sqrtPriceLimitX96.Sub(MAX_SQRT_RATIO, ONE_UINT_256)
+	}
+	return sqrtPriceLimitX96, nil
+}
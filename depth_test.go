@@ -0,0 +1,284 @@
+package uniswap_core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestLiquidityDistribution(t *testing.T) {
+	pool, ticks := newTestPool()
+	ticker := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+
+	type bucket struct {
+		tickLower int64
+		tickUpper int64
+		liquidity int64
+	}
+
+	cases := []struct {
+		tickLower int64
+		tickUpper int64
+		want      []bucket
+	}{
+		{-1800, 1800, []bucket{{-1800, -1200, 0}, {-1200, -600, 2e18}, {-600, 600, 3e18}, {600, 1200, 2e18}, {1200, 1800, 0}}},
+		{-900, 300, []bucket{{-900, -600, 2e18}, {-600, 300, 3e18}}},
+		{-600, 600, []bucket{{-600, 600, 3e18}}},
+		{700, 900, []bucket{{700, 900, 2e18}}},
+		{-1300, -1250, []bucket{{-1300, -1250, 0}}},
+	}
+
+	for _, c := range cases {
+		buckets, err := LiquidityDistribution(ticker, pool, big.NewInt(c.tickLower), big.NewInt(c.tickUpper))
+		if err != nil {
+			t.Fatalf("LiquidityDistribution(%d, %d): %s", c.tickLower, c.tickUpper, err)
+		}
+
+		got := make([]bucket, len(buckets))
+		for i, b := range buckets {
+			got[i] = bucket{b.TickLower.Int64(), b.TickUpper.Int64(), b.Liquidity.Int64()}
+		}
+
+		if len(got) != len(c.want) {
+			t.Errorf("LiquidityDistribution(%d, %d) = %v; want %v", c.tickLower, c.tickUpper, got, c.want)
+			continue
+		}
+
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("LiquidityDistribution(%d, %d) = %v; want %v", c.tickLower, c.tickUpper, got, c.want)
+				break
+			}
+		}
+	}
+
+	// the price is at tick 0, the range above it holds token0 and the range below it token1
+	buckets, err := LiquidityDistribution(ticker, pool, big.NewInt(-1800), big.NewInt(1800))
+	if err != nil {
+		t.Fatalf("LiquidityDistribution(): %s", err)
+	}
+
+	for _, b := range buckets {
+		var want0, want1 *big.Int
+
+		switch {
+		case b.TickUpper.Sign() <= 0:
+			want0, want1 = big.NewInt(0), GetAmount1DeltaRoundingUp(b.SqrtPriceLowerX96, b.SqrtPriceUpperX96, b.Liquidity, false)
+		case b.TickLower.Sign() >= 0:
+			want0, want1 = GetAmount0DeltaRoundingUp(b.SqrtPriceLowerX96, b.SqrtPriceUpperX96, b.Liquidity, false), big.NewInt(0)
+		default:
+			want0 = GetAmount0DeltaRoundingUp(pool.SqrtPrice.Val, b.SqrtPriceUpperX96, b.Liquidity, false)
+			want1 = GetAmount1DeltaRoundingUp(b.SqrtPriceLowerX96, pool.SqrtPrice.Val, b.Liquidity, false)
+		}
+
+		if b.Amount0.Cmp(want0) != 0 || b.Amount1.Cmp(want1) != 0 {
+			t.Errorf("LiquidityBucket [%d, %d) amounts = %d, %d; want %d, %d",
+				b.TickLower, b.TickUpper, b.Amount0, b.Amount1, want0, want1)
+		}
+	}
+}
+
+func TestLiquidityDistributionInvalid(t *testing.T) {
+	pool, ticks := newTestPool()
+	ticker := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+
+	windows := [][2]*big.Int{
+		{big.NewInt(600), big.NewInt(600)},
+		{big.NewInt(600), big.NewInt(-600)},
+		{big.NewInt(0).Sub(MIN_TICK, ONE_UINT_256), big.NewInt(0)},
+		{big.NewInt(0), big.NewInt(0).Add(MAX_TICK, ONE_UINT_256)},
+	}
+
+	for _, w := range windows {
+		if _, err := LiquidityDistribution(ticker, pool, w[0], w[1]); !errors.Is(err, ErrTickRangeInvalid) {
+			t.Errorf("LiquidityDistribution(%d, %d) error = %v; want %v", w[0], w[1], err, ErrTickRangeInvalid)
+		}
+	}
+}
+
+func TestLiquidityDistributionFixtures(t *testing.T) {
+	for _, name := range poolFixtures {
+		pool, ticks := loadPoolFixture(t, name)
+		spacing := pool.MustFeeTierToTickSpacing()
+		storage := NewTickStorage(ticks, spacing)
+
+		want, err := LiquidityDistribution(storage, pool, MIN_TICK, MAX_TICK)
+		if err != nil {
+			t.Fatalf("%s: LiquidityDistribution(): %s", name, err)
+		}
+
+		// the bitmap stops at the word boundaries, the buckets are split at the initialized ticks only
+		for _, ticker := range []TickReader{NewTickBitmap(storage), NewTickList(ticks, spacing)} {
+			buckets, err := LiquidityDistribution(ticker, pool, MIN_TICK, MAX_TICK)
+			if err != nil {
+				t.Fatalf("%s: LiquidityDistribution() with %T: %s", name, ticker, err)
+			}
+
+			if len(buckets) != len(want) {
+				t.Errorf("%s: len(LiquidityDistribution()) with %T = %d; want %d", name, ticker, len(buckets), len(want))
+				continue
+			}
+
+			for i := range buckets {
+				if buckets[i].TickLower.Cmp(want[i].TickLower) != 0 || buckets[i].Liquidity.Cmp(want[i].Liquidity) != 0 ||
+					buckets[i].Amount0.Cmp(want[i].Amount0) != 0 || buckets[i].Amount1.Cmp(want[i].Amount1) != 0 {
+					t.Errorf("%s: LiquidityDistribution()[%d] with %T = %+v; want %+v", name, i, ticker, buckets[i], want[i])
+				}
+			}
+		}
+
+		// the swap which drains the pool pays out the amounts locked in the buckets, the steps are the same
+		total0, total1 := big.NewInt(0), big.NewInt(0)
+		for _, b := range want {
+			total0.Add(total0, b.Amount0)
+			total1.Add(total1, b.Amount1)
+		}
+
+		// enough to reach the bounds of the price in the pools of the fixtures
+		amountIn := big.NewInt(0).Lsh(ONE_UINT_256, 200)
+
+		for _, zeroForOne := range []bool{true, false} {
+			res, err := DoSwap(zeroForOne, amountIn, big.NewInt(0), storage, pool)
+			if err != nil {
+				t.Fatalf("%s: DoSwap(%t): %s", name, zeroForOne, err)
+			}
+
+			amountOut, want := big.NewInt(0).Neg(res.Amount0), total0
+			if zeroForOne {
+				amountOut, want = big.NewInt(0).Neg(res.Amount1), total1
+			}
+
+			if amountOut.Cmp(want) != 0 {
+				t.Errorf("%s: DoSwap(%t) amount out = %d; want %d locked in the buckets", name, zeroForOne, amountOut, want)
+			}
+		}
+	}
+}
+
+func TestDepthAtPriceImpact(t *testing.T) {
+	pool, ticks := newTestPool()
+	ticker := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+	liquidity := pool.Liquidity.Val
+
+	// 1% moves the price by ~100 ticks within [-600, 600], the amount out is the one of the range
+	for _, zeroForOne := range []bool{true, false} {
+		amount0, amount1, err := DepthAtPriceImpact(pool, ticker, zeroForOne, big.NewInt(100))
+		if err != nil {
+			t.Fatalf("DepthAtPriceImpact(%t, 100): %s", zeroForOne, err)
+		}
+
+		limit, _ := sqrtPriceAtImpact(pool.SqrtPrice.Val, zeroForOne, big.NewInt(100))
+
+		amountIn, amountOut := amount0, amount1
+		wantOut := GetAmount1DeltaRoundingUp(limit, pool.SqrtPrice.Val, liquidity, false)
+		if !zeroForOne {
+			amountIn, amountOut = amount1, amount0
+			wantOut = GetAmount0DeltaRoundingUp(pool.SqrtPrice.Val, limit, liquidity, false)
+		}
+
+		if amountIn.Sign() <= 0 || amountOut.Cmp(big.NewInt(0).Neg(wantOut)) != 0 {
+			t.Errorf("DepthAtPriceImpact(%t, 100) = %d, %d; want the amount out %d", zeroForOne, amount0, amount1, wantOut)
+		}
+	}
+
+	for _, c := range []struct {
+		zeroForOne bool
+		bps        int64
+	}{{true, 0}, {true, -1}, {true, 10000}, {false, 0}} {
+		if _, _, err := DepthAtPriceImpact(pool, ticker, c.zeroForOne, big.NewInt(c.bps)); !errors.Is(err, ErrPriceLimitInvalid) {
+			t.Errorf("DepthAtPriceImpact(%t, %d) error = %v; want %v", c.zeroForOne, c.bps, err, ErrPriceLimitInvalid)
+		}
+	}
+}
+
+func TestSqrtPriceAtImpact(t *testing.T) {
+	sqrtPriceX96 := GetSqrtRatioAtTick(big.NewInt(-12345))
+	price := big.NewInt(0).Mul(sqrtPriceX96, sqrtPriceX96)
+
+	for _, bps := range []int64{1, 200, 9999, 10000, 1e6} {
+		for _, zeroForOne := range []bool{true, false} {
+			limit, err := sqrtPriceAtImpact(sqrtPriceX96, zeroForOne, big.NewInt(bps))
+			if zeroForOne && bps >= 10000 {
+				if !errors.Is(err, ErrPriceLimitInvalid) {
+					t.Errorf("sqrtPriceAtImpact(%t, %d) error = %v; want %v", zeroForOne, bps, err, ErrPriceLimitInvalid)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("sqrtPriceAtImpact(%t, %d): %s", zeroForOne, bps, err)
+			}
+
+			// the limit is the closest sqrt price to the current one which moves the price by at least the bps
+			factor := big.NewInt(10000 + bps)
+			if zeroForOne {
+				factor = big.NewInt(10000 - bps)
+			}
+			target := big.NewInt(0).Mul(price, factor)
+
+			limitPrice := big.NewInt(0).Mul(limit, limit)
+			limitPrice.Mul(limitPrice, big.NewInt(10000))
+
+			beyond := big.NewInt(0).Sub(limit, ONE_UINT_256)
+			if !zeroForOne {
+				beyond.Add(limit, ONE_UINT_256)
+			}
+			beyondPrice := big.NewInt(0).Mul(beyond, beyond)
+			beyondPrice.Mul(beyondPrice, big.NewInt(10000))
+
+			if zeroForOne && (limitPrice.Cmp(target) < 0 || beyondPrice.Cmp(target) >= 0) ||
+				!zeroForOne && (limitPrice.Cmp(target) > 0 || beyondPrice.Cmp(target) <= 0) {
+				t.Errorf("sqrtPriceAtImpact(%t, %d) = %d is not the closest limit to the price", zeroForOne, bps, limit)
+			}
+		}
+	}
+
+	// the limits out of the range of the price are clamped
+	minLimit := big.NewInt(0).Add(MIN_SQRT_RATIO, ONE_UINT_256)
+	if limit, _ := sqrtPriceAtImpact(GetSqrtRatioAtTick(big.NewInt(-887000)), true, big.NewInt(9999)); limit.Cmp(minLimit) != 0 {
+		t.Errorf("sqrtPriceAtImpact(-887000, true, 9999) = %d; want %d", limit, minLimit)
+	}
+
+	maxLimit := big.NewInt(0).Sub(MAX_SQRT_RATIO, ONE_UINT_256)
+	if limit, _ := sqrtPriceAtImpact(GetSqrtRatioAtTick(big.NewInt(887000)), false, big.NewInt(1e6)); limit.Cmp(maxLimit) != 0 {
+		t.Errorf("sqrtPriceAtImpact(887000, false, 1e6) = %d; want %d", limit, maxLimit)
+	}
+}
+
+func TestDepthAtPriceImpactFixtures(t *testing.T) {
+	for _, name := range poolFixtures {
+		pool, ticks := loadPoolFixture(t, name)
+		ticker := NewTickBitmap(NewTickStorage(ticks, pool.MustFeeTierToTickSpacing()))
+
+		for _, zeroForOne := range []bool{true, false} {
+			prevIn := big.NewInt(0)
+
+			// the deeper impact takes more input and the ±2% depth is the swap to the limit
+			for _, bps := range []int64{1, 10, 50, 200, 500, 2000} {
+				amount0, amount1, err := DepthAtPriceImpact(pool, ticker, zeroForOne, big.NewInt(bps))
+				if err != nil {
+					t.Fatalf("%s: DepthAtPriceImpact(%t, %d): %s", name, zeroForOne, bps, err)
+				}
+
+				amountIn := amount1
+				if zeroForOne {
+					amountIn = amount0
+				}
+
+				if amountIn.Cmp(prevIn) < 0 {
+					t.Errorf("%s: DepthAtPriceImpact(%t, %d) amount in = %d; want at least %d", name, zeroForOne, bps, amountIn, prevIn)
+				}
+				prevIn = amountIn
+
+				limit, _ := sqrtPriceAtImpact(pool.SqrtPrice.Val, zeroForOne, big.NewInt(bps))
+				res, err := DoSwap(zeroForOne, MAX_INT_256, limit, ticker, pool)
+				if err != nil {
+					t.Fatalf("%s: DoSwap(%t, %d): %s", name, zeroForOne, limit, err)
+				}
+
+				if !res.PriceLimitReached || res.Amount0.Cmp(amount0) != 0 || res.Amount1.Cmp(amount1) != 0 {
+					t.Errorf("%s: DepthAtPriceImpact(%t, %d) = %d, %d; want %d, %d", name, zeroForOne, bps, amount0, amount1, res.Amount0, res.Amount1)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package uniswap_core
+
+import "errors"
+
+// Errors mirror the revert reasons of the Uniswap V3 contracts, the details are wrapped with fmt.Errorf("%w")
+var (
+	// 'AS': amount specified is zero
+	ErrAmountSpecifiedZero = errors.New("uniswap_core: amount specified is zero")
+	// 'T': tick is out of [MIN_TICK, MAX_TICK]
+	ErrTickOutOfRange = errors.New("uniswap_core: tick out of range")
+	// 'R': sqrt price is out of [MIN_SQRT_RATIO, MAX_SQRT_RATIO)
+	ErrPriceOutOfRange = errors.New("uniswap_core: sqrt price out of range")
+	// there is not enough liquidity to swap or burn the requested amount
+	ErrInsufficientLiquidity = errors.New("uniswap_core: insufficient liquidity")
+	// 'SPL': sqrt price limit is on the wrong side of the current price or out of range
+	ErrPriceLimitInvalid = errors.New("uniswap_core: invalid sqrt price limit")
+	// 'LOK': pool is not initialized
+	ErrPoolLocked = errors.New("uniswap_core: pool is locked")
+	// 'TLU', 'TLM', 'TUM': tick range of a position is invalid
+	ErrTickRangeInvalid = errors.New("uniswap_core: invalid tick range")
+	// the result does not fit into the fixed-width Solidity type
+	ErrOverflow = errors.New("uniswap_core: overflow")
+	// snapshot is written by an unknown version of the format or its state is incomplete
+	ErrSnapshotInvalid = errors.New("uniswap_core: invalid pool snapshot")
+	// required field of the pool is missing or null, e.g. in the subgraph response
+	ErrPoolInvalid = errors.New("uniswap_core: invalid pool")
+	// fee tier has no tick spacing assigned
+	ErrFeeTierUnknown = errors.New("uniswap_core: unknown fee tier")
+	// fee protocol denominator is neither 0 nor in [4, 10]
+	ErrFeeProtocolInvalid = errors.New("uniswap_core: invalid fee protocol")
+	// liquidity to mint is not positive or liquidity to burn is negative
+	ErrLiquidityInvalid = errors.New("uniswap_core: invalid liquidity amount")
+	// requested amount to collect is negative
+	ErrAmountRequestedInvalid = errors.New("uniswap_core: invalid requested amount")
+)
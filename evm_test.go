@@ -0,0 +1,563 @@
+//go:build evm
+
+package uniswap_core
+
+// Differential fuzz tests of the port against the compiled Uniswap V3 contracts executed by the in-process EVM
+// of go-ethereum. The pinned hardhat artifacts of the contracts are read from testdata/evm, no network is needed,
+// a missing artifact fails the test. testdata/evm/build.sh regenerates them (see testdata/evm/README.md).
+// Run the seed corpus with: go test -tags evm -run EVM ./...
+// Fuzz with: go test -tags evm -run '^$' -fuzz FuzzEVMDoSwap ./...
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+)
+
+type evmArtifact struct {
+	ABI      json.RawMessage `json:"abi"`
+	Bytecode string          `json:"bytecode"`
+}
+
+type evmContract struct {
+	abi     abi.ABI
+	address common.Address
+	cfg     *runtime.Config
+}
+
+func newEVMConfig() *runtime.Config {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	return &runtime.Config{
+		Origin:      common.HexToAddress("0x00000000000000000000000000000000000a11ce"),
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(1640995200),
+		State:       statedb}
+}
+
+func loadEVMArtifact(tb testing.TB, name string) (abi.ABI, []byte) {
+	tb.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "evm", name+".json"))
+	if err != nil {
+		tb.Fatalf("loadEVMArtifact(%s): %s, see testdata/evm/README.md", name, err)
+	}
+
+	var artifact evmArtifact
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		tb.Fatalf("loadEVMArtifact(%s): %s", name, err)
+	}
+
+	parsed, err := abi.JSON(bytes.NewReader(artifact.ABI))
+	if err != nil {
+		tb.Fatalf("loadEVMArtifact(%s): %s", name, err)
+	}
+
+	return parsed, common.FromHex(artifact.Bytecode)
+}
+
+func deployEVMContract(tb testing.TB, cfg *runtime.Config, name string, args ...interface{}) *evmContract {
+	tb.Helper()
+
+	parsed, code := loadEVMArtifact(tb, name)
+
+	ctorArgs, err := parsed.Pack("", args...)
+	if err != nil {
+		tb.Fatalf("deployEVMContract(%s): %s", name, err)
+	}
+
+	_, address, _, err := runtime.Create(append(code, ctorArgs...), cfg)
+	if err != nil {
+		tb.Fatalf("deployEVMContract(%s): %s", name, err)
+	}
+
+	return &evmContract{abi: parsed, address: address, cfg: cfg}
+}
+
+// Calls the method of the contract, the error holds the revert reason
+func (c *evmContract) call(method string, args ...interface{}) ([]interface{}, error) {
+	input, err := c.abi.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, _, err := runtime.Call(c.address, input, c.cfg)
+	if err != nil {
+		reason, _ := abi.UnpackRevert(ret)
+		return nil, fmt.Errorf("%s: %w (%q)", method, err, reason)
+	}
+
+	return c.abi.Unpack(method, ret)
+}
+
+func (c *evmContract) mustCall(tb testing.TB, method string, args ...interface{}) []interface{} {
+	tb.Helper()
+
+	out, err := c.call(method, args...)
+	if err != nil {
+		tb.Fatalf("%s", err)
+	}
+	return out
+}
+
+// evmUint maps the fuzzer bytes onto the uint of the given bit width, the higher bits are dropped
+func evmUint(b []byte, bits uint) *big.Int {
+	x := big.NewInt(0).SetBytes(b)
+	return x.And(x, GetMaxValue(bits))
+}
+
+// evmUint128 returns the uint128 hi << 64 | lo
+func evmUint128(hi uint64, lo uint64) *big.Int {
+	x := big.NewInt(0).SetUint64(hi)
+	x.Lsh(x, 64)
+	return x.Or(x, big.NewInt(0).SetUint64(lo))
+}
+
+// Checks that both the contract and the port either fail or return the same values
+func checkEVMResults(t *testing.T, call string, want []interface{}, wantErr error, got []*big.Int, err error) {
+	t.Helper()
+
+	if (wantErr == nil) != (err == nil) {
+		t.Errorf("%s error = %v; want %v", call, err, wantErr)
+		return
+	}
+
+	if wantErr != nil {
+		return
+	}
+
+	for i := range got {
+		if got[i].Cmp(want[i].(*big.Int)) != 0 {
+			t.Errorf("%s = %d; want %v", call, got, want)
+			return
+		}
+	}
+}
+
+func FuzzEVMGetSqrtRatioAtTick(f *testing.F) {
+	for _, tick := range []int32{int32(MIN_TICK.Int64()), int32(MAX_TICK.Int64()), 0, -1, 1,
+		int32(MIN_TICK.Int64()) - 1, int32(MAX_TICK.Int64()) + 1, -(1 << 23), 1<<23 - 1} {
+		f.Add(tick)
+	}
+
+	tickMath := deployEVMContract(f, newEVMConfig(), "TickMathTest")
+
+	f.Fuzz(func(t *testing.T, tick int32) {
+		// int24
+		tick = tick << 8 >> 8
+
+		want, wantErr := tickMath.call("getSqrtRatioAtTick", big.NewInt(int64(tick)))
+		got, err := TryGetSqrtRatioAtTick(big.NewInt(int64(tick)))
+		checkEVMResults(t, fmt.Sprintf("GetSqrtRatioAtTick(%d)", tick), want, wantErr, []*big.Int{got}, err)
+	})
+}
+
+func FuzzEVMGetTickAtSqrtRatio(f *testing.F) {
+	for _, sqrtPrice := range []*big.Int{MIN_SQRT_RATIO, MAX_SQRT_RATIO, big.NewInt(0).Sub(MAX_SQRT_RATIO, ONE_UINT_256),
+		big.NewInt(0).Sub(MIN_SQRT_RATIO, ONE_UINT_256), GetSqrtRatioAtTick(big.NewInt(0)), big.NewInt(0)} {
+		f.Add(sqrtPrice.Bytes())
+	}
+
+	tickMath := deployEVMContract(f, newEVMConfig(), "TickMathTest")
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		sqrtPrice := evmUint(b, 160)
+
+		want, wantErr := tickMath.call("getTickAtSqrtRatio", sqrtPrice)
+		got, err := TryGetTickAtSqrtRatio(sqrtPrice)
+		checkEVMResults(t, fmt.Sprintf("GetTickAtSqrtRatio(%d)", sqrtPrice), want, wantErr, []*big.Int{got}, err)
+	})
+}
+
+func FuzzEVMSqrtPriceMath(f *testing.F) {
+	f.Add(GetSqrtRatioAtTick(big.NewInt(0)).Bytes(), GetSqrtRatioAtTick(big.NewInt(600)).Bytes(),
+		uint64(0), uint64(1e18), big.NewInt(1e17).Bytes(), true)
+	f.Add(MAX_SQRT_RATIO.Bytes(), MIN_SQRT_RATIO.Bytes(), uint64(0xffffffffffffffff), uint64(0xffffffffffffffff),
+		MAX_UINT_256.Bytes(), false)
+	f.Add([]byte{}, []byte{0x01}, uint64(0), uint64(0), []byte{}, true)
+
+	sqrtPriceMath := deployEVMContract(f, newEVMConfig(), "SqrtPriceMathTest")
+
+	f.Fuzz(func(t *testing.T, p []byte, q []byte, liquidityHi uint64, liquidityLo uint64, a []byte, flag bool) {
+		sqrtPX96 := evmUint(p, 160)
+		sqrtQX96 := evmUint(q, 160)
+		liquidity := evmUint128(liquidityHi, liquidityLo)
+		amount := evmUint(a, 256)
+
+		want, wantErr := sqrtPriceMath.call("getNextSqrtPriceFromInput", sqrtPX96, liquidity, amount, flag)
+		got, err := TryGetNextSqrtPriceFromInput(sqrtPX96, liquidity, amount, flag, true)
+		checkEVMResults(t, fmt.Sprintf("GetNextSqrtPriceFromInput(%d, %d, %d, %t)", sqrtPX96, liquidity, amount, flag),
+			want, wantErr, []*big.Int{got}, err)
+
+		want, wantErr = sqrtPriceMath.call("getNextSqrtPriceFromOutput", sqrtPX96, liquidity, amount, flag)
+		got, err = TryGetNextSqrtPriceFromOutput(sqrtPX96, liquidity, amount, flag, true)
+		checkEVMResults(t, fmt.Sprintf("GetNextSqrtPriceFromOutput(%d, %d, %d, %t)", sqrtPX96, liquidity, amount, flag),
+			want, wantErr, []*big.Int{got}, err)
+
+		want, wantErr = sqrtPriceMath.call("getAmount0Delta", sqrtPX96, sqrtQX96, liquidity, flag)
+		got, err = TryGetAmount0DeltaRoundingUp(sqrtPX96, sqrtQX96, liquidity, flag, true)
+		checkEVMResults(t, fmt.Sprintf("GetAmount0DeltaRoundingUp(%d, %d, %d, %t)", sqrtPX96, sqrtQX96, liquidity, flag),
+			want, wantErr, []*big.Int{got}, err)
+
+		want, wantErr = sqrtPriceMath.call("getAmount1Delta", sqrtPX96, sqrtQX96, liquidity, flag)
+		got, err = TryGetAmount1DeltaRoundingUp(sqrtPX96, sqrtQX96, liquidity, flag, true)
+		checkEVMResults(t, fmt.Sprintf("GetAmount1DeltaRoundingUp(%d, %d, %d, %t)", sqrtPX96, sqrtQX96, liquidity, flag),
+			want, wantErr, []*big.Int{got}, err)
+	})
+}
+
+func FuzzEVMComputeSwapStep(f *testing.F) {
+	f.Add(GetSqrtRatioAtTick(big.NewInt(0)).Bytes(), GetSqrtRatioAtTick(big.NewInt(-600)).Bytes(),
+		uint64(0), uint64(1e18), big.NewInt(1e15).Bytes(), true, uint32(3000))
+	f.Add(GetSqrtRatioAtTick(big.NewInt(0)).Bytes(), GetSqrtRatioAtTick(big.NewInt(600)).Bytes(),
+		uint64(0), uint64(1e18), big.NewInt(1e15).Bytes(), false, uint32(500))
+	f.Add(MIN_SQRT_RATIO.Bytes(), MAX_SQRT_RATIO.Bytes(), uint64(0xffffffffffffffff), uint64(0xffffffffffffffff),
+		MAX_INT_256.Bytes(), true, uint32(999999))
+
+	swapMath := deployEVMContract(f, newEVMConfig(), "SwapMathTest")
+
+	f.Fuzz(func(t *testing.T, current []byte, target []byte, liquidityHi uint64, liquidityLo uint64,
+		amount []byte, exactIn bool, feePips uint32) {
+		sqrtPriceRaw := evmUint(current, 160)
+		sqrtPriceTargetRaw := evmUint(target, 160)
+		liquidity := evmUint128(liquidityHi, liquidityLo)
+
+		amountRemaining := evmUint(amount, 255)
+		if !exactIn {
+			amountRemaining.Neg(amountRemaining)
+		}
+
+		fee := big.NewInt(int64(feePips % 1e6))
+
+		call := fmt.Sprintf("ComputeSwapStep(%d, %d, %d, %d, %d)",
+			sqrtPriceRaw, sqrtPriceTargetRaw, liquidity, amountRemaining, fee)
+
+		want, wantErr := swapMath.call("computeSwapStep", sqrtPriceRaw, sqrtPriceTargetRaw, liquidity, amountRemaining, fee)
+		sqrtQ, amountIn, amountOut, feeAmount, err := TryComputeSwapStep(
+			sqrtPriceRaw, sqrtPriceTargetRaw, liquidity, amountRemaining, fee, true)
+		checkEVMResults(t, call, want, wantErr, []*big.Int{sqrtQ, amountIn, amountOut, feeAmount}, err)
+	})
+}
+
+// evmPool is a pool deployed by the factory with the test tokens and the callee used to mint and swap
+type evmPool struct {
+	pool           *evmContract
+	token0, token1 *evmContract
+	callee         *evmContract
+}
+
+// newEVMPool deploys the pool with the fee tier, the price and the protocol fee of the given pool, without liquidity
+func newEVMPool(tb testing.TB, p *Pool) *evmPool {
+	cfg := newEVMConfig()
+	fee := p.FeeTier.Val
+
+	supply := big.NewInt(0).Lsh(ONE_UINT_256, 255)
+	tokenA := deployEVMContract(tb, cfg, "TestERC20", supply)
+	tokenB := deployEVMContract(tb, cfg, "TestERC20", supply)
+	factory := deployEVMContract(tb, cfg, "UniswapV3Factory")
+	callee := deployEVMContract(tb, cfg, "TestUniswapV3Callee")
+	poolABI, _ := loadEVMArtifact(tb, "UniswapV3Pool")
+
+	// the fee tiers enabled by the owner after the deployment, e.g. 100
+	if factory.mustCall(tb, "feeAmountTickSpacing", fee)[0].(*big.Int).Sign() == 0 {
+		factory.mustCall(tb, "enableFeeAmount", fee, p.MustFeeTierToTickSpacing())
+	}
+
+	out := factory.mustCall(tb, "createPool", tokenA.address, tokenB.address, fee)
+	pool := &evmContract{abi: poolABI, address: out[0].(common.Address), cfg: cfg}
+	pool.mustCall(tb, "initialize", p.SqrtPrice.Val)
+
+	if p.FeeProtocol.Val != nil && p.FeeProtocol.Val.Sign() != 0 {
+		feeProtocol := uint8(p.FeeProtocol.Val.Uint64())
+		pool.mustCall(tb, "setFeeProtocol", feeProtocol&0x0f, feeProtocol>>4)
+	}
+
+	token0, token1 := tokenA, tokenB
+	if pool.mustCall(tb, "token0")[0].(common.Address) != tokenA.address {
+		token0, token1 = tokenB, tokenA
+	}
+
+	for _, token := range []*evmContract{token0, token1} {
+		token.mustCall(tb, "approve", callee.address, MAX_UINT_256)
+	}
+
+	return &evmPool{pool: pool, token0: token0, token1: token1, callee: callee}
+}
+
+// Returns the balances of the pool in token0 and token1
+func (p *evmPool) balances(tb testing.TB) (*big.Int, *big.Int) {
+	return p.token0.mustCall(tb, "balanceOf", p.pool.address)[0].(*big.Int),
+		p.token1.mustCall(tb, "balanceOf", p.pool.address)[0].(*big.Int)
+}
+
+// Runs the callee method and returns the changes of the pool balances
+func (p *evmPool) run(tb testing.TB, method string, args ...interface{}) (amount0 *big.Int, amount1 *big.Int, err error) {
+	before0, before1 := p.balances(tb)
+
+	if _, err = p.callee.call(method, args...); err != nil {
+		return
+	}
+
+	after0, after1 := p.balances(tb)
+	return after0.Sub(after0, before0), after1.Sub(after1, before1), nil
+}
+
+// evmPositions are minted into both pools before the swaps, the ticks are in several words of the bitmap
+// of the tick spacing 60, so the swaps of the pool stop at the word boundaries
+var evmPositions = []struct {
+	tickLower, tickUpper int64
+	liquidity            string
+}{
+	{-600, 600, "1000000000000000000"},
+	{-1200, 1200, "2000000000000000000"},
+	{-60, 120, "500000000000000000"},
+	{600, 1800, "300000000000000000"},
+	{-46080, -15420, "100000000000000000"},
+	{15360, 30780, "100000000000000000"},
+	{-887220, 887220, "10000000000000000"},
+}
+
+var evmSwapMethods = map[[2]bool]string{
+	{true, true}:   "swapExact0For1",
+	{true, false}:  "swap0ForExact1",
+	{false, true}:  "swapExact1For0",
+	{false, false}: "swap1ForExact0",
+}
+
+// evmSwap is the length of the encoded swap of FuzzEVMDoSwap: the flags byte and the uint64 amount.
+// The flags are zeroForOne (bit 0), exact input (bit 1) and the shift of the amount (bits 2-6)
+const evmSwap = 9
+
+// FuzzEVMDoSwap mints evmPositions and runs the sequence of swaps encoded in the input against
+// the pool contract and the strict PoolSimulator stepping through the TickBitmap like the pool does.
+// The amounts, the pool state and the ticks are compared after every swap, the fees of the positions
+// are compared after the swaps by burning and collecting them
+func FuzzEVMDoSwap(f *testing.F) {
+	encode := func(swaps ...[3]uint64) []byte {
+		b := make([]byte, evmSwap*len(swaps))
+		for i, s := range swaps {
+			b[i*evmSwap] = byte(s[0])
+			binary.BigEndian.PutUint64(b[i*evmSwap+1:], s[1])
+		}
+		return b
+	}
+
+	f.Add(encode([3]uint64{0b11, 1e18}, [3]uint64{0b10, 1e18}))
+	f.Add(encode([3]uint64{0b01, 1e15}, [3]uint64{0b00, 1e15}, [3]uint64{0b11, 1000}))
+	// through the word boundaries down to the full range liquidity and back
+	f.Add(encode([3]uint64{0b11 | 8<<2, 1e18}, [3]uint64{0b10 | 8<<2, 1e18}))
+	f.Add(encode([3]uint64{0b10 | 8<<2, 1e18}, [3]uint64{0b01 | 4<<2, 1e18}))
+	// to the price limits
+	f.Add(encode([3]uint64{0b11 | 31<<2, 0xffffffffffffffff}, [3]uint64{0b10 | 31<<2, 0xffffffffffffffff}))
+	// zero amount reverts with 'AS'
+	f.Add(encode([3]uint64{0b11, 0}))
+
+	f.Fuzz(func(t *testing.T, swaps []byte) {
+		// the pool without liquidity at tick 0 like the freshly initialized contract
+		pool, _ := newTestPool()
+		pool.Liquidity = BigInt{Val: big.NewInt(0)}
+		sim, err := NewPoolSimulator(pool, NewTickStorage(nil, pool.MustFeeTierToTickSpacing()))
+		if err != nil {
+			t.Fatalf("NewPoolSimulator(): %s", err)
+		}
+		sim.Strict = true
+
+		evm := newEVMPool(t, pool)
+		origin := evm.pool.cfg.Origin
+
+		for _, pos := range evmPositions {
+			tickLower, tickUpper := big.NewInt(pos.tickLower), big.NewInt(pos.tickUpper)
+			liquidity, _ := big.NewInt(0).SetString(pos.liquidity, 10)
+
+			want0, want1, wantErr := evm.run(t, "mint", evm.pool.address, origin, tickLower, tickUpper, liquidity)
+			got0, got1, err := sim.Mint(origin.Hex(), tickLower, tickUpper, liquidity)
+			checkEVMResults(t, fmt.Sprintf("Mint(%d, %d, %d)", tickLower, tickUpper, liquidity),
+				[]interface{}{want0, want1}, wantErr, []*big.Int{got0, got1}, err)
+		}
+
+		for ; len(swaps) >= evmSwap; swaps = swaps[evmSwap:] {
+			flags := swaps[0]
+			zeroForOne := flags&1 != 0
+			exactInput := flags&2 != 0
+
+			amount := big.NewInt(0).SetUint64(binary.BigEndian.Uint64(swaps[1:evmSwap]))
+			amount.Lsh(amount, uint(flags>>2&0x1f))
+
+			sqrtPriceLimitX96 := big.NewInt(0).Add(MIN_SQRT_RATIO, ONE_UINT_256)
+			if !zeroForOne {
+				sqrtPriceLimitX96.Sub(MAX_SQRT_RATIO, ONE_UINT_256)
+			}
+
+			amountSpecified := big.NewInt(0).Set(amount)
+			if !exactInput {
+				amountSpecified.Neg(amount)
+			}
+
+			call := fmt.Sprintf("Swap(%t, %d)", zeroForOne, amountSpecified)
+
+			want0, want1, wantErr := evm.run(t, evmSwapMethods[[2]bool{zeroForOne, exactInput}],
+				evm.pool.address, amount, origin, sqrtPriceLimitX96)
+			res, err := sim.Swap(zeroForOne, amountSpecified, sqrtPriceLimitX96)
+
+			if err != nil || wantErr != nil {
+				checkEVMResults(t, call, nil, wantErr, nil, err)
+				continue
+			}
+
+			checkEVMResults(t, call, []interface{}{want0, want1}, nil, []*big.Int{res.Amount0, res.Amount1}, nil)
+			checkEVMPoolState(t, call, evm, sim)
+		}
+
+		for _, pos := range evmPositions {
+			tickLower, tickUpper := big.NewInt(pos.tickLower), big.NewInt(pos.tickUpper)
+			liquidity, _ := big.NewInt(0).SetString(pos.liquidity, 10)
+			liquidity.Rsh(liquidity, 1)
+
+			want, wantErr := evm.pool.call("burn", tickLower, tickUpper, liquidity)
+			got0, got1, err := sim.Burn(origin.Hex(), tickLower, tickUpper, liquidity)
+			checkEVMResults(t, fmt.Sprintf("Burn(%d, %d, %d)", tickLower, tickUpper, liquidity),
+				want, wantErr, []*big.Int{got0, got1}, err)
+
+			want, wantErr = evm.pool.call("collect", origin, tickLower, tickUpper, MAX_UINT_128, MAX_UINT_128)
+			got0, got1, err = sim.Collect(origin.Hex(), tickLower, tickUpper, MAX_UINT_128, MAX_UINT_128)
+			checkEVMResults(t, fmt.Sprintf("Collect(%d, %d)", tickLower, tickUpper),
+				want, wantErr, []*big.Int{got0, got1}, err)
+		}
+
+		checkEVMPoolState(t, "Burn", evm, sim)
+	})
+}
+
+// Compares the slot0, the liquidity, the fee growth and the ticks of the positions of the pool contract and the simulator
+func checkEVMPoolState(t *testing.T, call string, evm *evmPool, sim *PoolSimulator) {
+	t.Helper()
+
+	slot0 := evm.pool.mustCall(t, "slot0")
+	liquidity := evm.pool.mustCall(t, "liquidity")
+	feeGrowthGlobal0X128 := evm.pool.mustCall(t, "feeGrowthGlobal0X128")
+	feeGrowthGlobal1X128 := evm.pool.mustCall(t, "feeGrowthGlobal1X128")
+
+	checkEVMResults(t, call+" state",
+		[]interface{}{slot0[0], slot0[1], liquidity[0], feeGrowthGlobal0X128[0], feeGrowthGlobal1X128[0]}, nil,
+		[]*big.Int{sim.Slot0.SqrtPriceX96, sim.Slot0.TickCurrent, sim.Slot0.Liquidity,
+			sim.Slot0.FeeGrowthGlobal0X128, sim.Slot0.FeeGrowthGlobal1X128}, nil)
+
+	for _, pos := range evmPositions {
+		for _, key := range []int64{pos.tickLower, pos.tickUpper} {
+			tick := big.NewInt(key)
+
+			// liquidityGross, liquidityNet, feeGrowthOutside0X128, feeGrowthOutside1X128
+			want := evm.pool.mustCall(t, "ticks", tick)[:4]
+			got := []*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0)}
+			if data, ok := sim.Ticks.Ticks[key]; ok {
+				got = []*big.Int{data.LiquidityGross.Val, data.LiquidityNet.Val,
+					data.FeeGrowthOutside0X128.Val, data.FeeGrowthOutside1X128.Val}
+			}
+
+			checkEVMResults(t, fmt.Sprintf("%s tick %d", call, tick), want, nil, got, nil)
+
+			if initialized := want[0].(*big.Int).Sign() != 0; sim.Bitmap.IsInitialized(tick) != initialized {
+				t.Errorf("%s TickBitmap.IsInitialized(%d) = %t; want %t", call, tick, !initialized, initialized)
+			}
+		}
+	}
+}
+
+// newEVMFixturePool deploys the pool of the fixture and mints the liquidity of its ticks as the positions
+// between the adjacent ticks, so the initialized ticks and their liquidityNet are the ones of the fixture.
+// The fee growth starts from zero, unlike in the fixture
+func newEVMFixturePool(t *testing.T, pool *Pool, ticks []Tick) *evmPool {
+	t.Helper()
+
+	evm := newEVMPool(t, pool)
+	origin := evm.pool.cfg.Origin
+
+	if tick := evm.pool.mustCall(t, "slot0")[1].(*big.Int); tick.Cmp(pool.Tick.Val) != 0 {
+		t.Fatalf("fixture tick %d; want %d of the sqrt price %d", pool.Tick.Val, tick, pool.SqrtPrice.Val)
+	}
+
+	sorted := make([]Tick, len(ticks))
+	copy(sorted, ticks)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TickIdx.Val.Cmp(sorted[j].TickIdx.Val) < 0 })
+
+	liquidity := big.NewInt(0)
+	for i := range sorted {
+		liquidity.Add(liquidity, sorted[i].LiquidityNet.Val)
+		if liquidity.Sign() < 0 || liquidity.Sign() > 0 && i+1 == len(sorted) {
+			t.Fatalf("fixture liquidity %d after tick %d; want the liquidity of the positions", liquidity, sorted[i].TickIdx.Val)
+		}
+
+		if liquidity.Sign() > 0 {
+			tickLower, tickUpper := sorted[i].TickIdx.Val, sorted[i+1].TickIdx.Val
+			if _, _, err := evm.run(t, "mint", evm.pool.address, origin, tickLower, tickUpper, liquidity); err != nil {
+				t.Fatalf("Mint(%d, %d, %d): %s", tickLower, tickUpper, liquidity, err)
+			}
+		}
+	}
+
+	if got := evm.pool.mustCall(t, "liquidity")[0].(*big.Int); got.Cmp(pool.Liquidity.Val) != 0 {
+		t.Fatalf("fixture liquidity %d; want %d in the contract", pool.Liquidity.Val, got)
+	}
+
+	return evm
+}
+
+// TestEVMSwapGolden replays the cases of testdata/golden against the pool contract holding the liquidity
+// of the fixture, so the goldens are checked against the contract and not only recorded from the port.
+// Every case runs on the new pool
+func TestEVMSwapGolden(t *testing.T) {
+	for _, name := range poolFixtures {
+		t.Run(name, func(t *testing.T) {
+			pool, ticks := loadPoolFixture(t, name)
+
+			for _, c := range loadSwapGolden(t, name) {
+				evm := newEVMFixturePool(t, pool, ticks)
+				origin := evm.pool.cfg.Origin
+
+				sqrtPriceLimitX96 := big.NewInt(0).Set(c.SqrtPriceLimitX96)
+				if sqrtPriceLimitX96.Sign() == 0 && c.ZeroForOne {
+					sqrtPriceLimitX96.Add(MIN_SQRT_RATIO, ONE_UINT_256)
+				} else if sqrtPriceLimitX96.Sign() == 0 {
+					sqrtPriceLimitX96.Sub(MAX_SQRT_RATIO, ONE_UINT_256)
+				}
+
+				call := fmt.Sprintf("%s: Swap(%t, %d, %d)", name, c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96)
+				method := evmSwapMethods[[2]bool{c.ZeroForOne, c.AmountSpecified.Sign() > 0}]
+
+				want0, want1, err := evm.run(t, method, evm.pool.address,
+					big.NewInt(0).Abs(c.AmountSpecified), origin, sqrtPriceLimitX96)
+				if err != nil {
+					t.Errorf("%s: %s", call, err)
+					continue
+				}
+
+				// the fee growth and the protocol fees of the contract start from zero
+				slot0 := evm.pool.mustCall(t, "slot0")
+				liquidity := evm.pool.mustCall(t, "liquidity")
+				feeGrowthGlobalX128 := evm.pool.mustCall(t, "feeGrowthGlobal1X128")
+				protocolFees := evm.pool.mustCall(t, "protocolFees")
+				protocolFee := protocolFees[1]
+				if c.ZeroForOne {
+					feeGrowthGlobalX128 = evm.pool.mustCall(t, "feeGrowthGlobal0X128")
+					protocolFee = protocolFees[0]
+				}
+
+				checkEVMResults(t, call, []interface{}{want0, want1, slot0[0], slot0[1], liquidity[0], feeGrowthGlobalX128[0], protocolFee}, nil,
+					[]*big.Int{c.Result.Amount0, c.Result.Amount1, c.Result.SqrtPriceX96, c.Result.Tick, c.Result.Liquidity,
+						c.Result.FeeGrowthGlobalX128Delta, c.Result.ProtocolFee}, nil)
+			}
+		})
+	}
+}
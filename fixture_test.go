@@ -0,0 +1,191 @@
+package uniswap_core
+
+import (
+	"encoding/json"
+	"flag"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Run go test -run Golden -update to write the golden files of the new fixtures or to rewrite them after
+// an intended change of the swap results. The goldens are the output of the port itself, i.e. they catch
+// regressions only; TestEVMSwapGolden (go test -tags evm) compares them with the pool contract
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// poolFixture is a snapshot of a pool in the format of the subgraph responses of GetPool and GetTicks,
+// stored in testdata/pools/<name>.json as {"block": ..., "id": ..., "pool": {...}, "ticks": [...]}.
+// The recorded fixtures (see TestRecordPoolFixtures) hold the pool id and the block they were queried at,
+// the synthetic ones have neither, see testdata/pools/README.md
+type poolFixture struct {
+	Block BigInt
+	Id    string
+	Pool  Pool
+	Ticks []Tick
+}
+
+// poolFixtures are the names of the files in testdata/pools
+var poolFixtures = func() []string {
+	paths, _ := filepath.Glob(filepath.Join("testdata", "pools", "*.json"))
+
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = strings.TrimSuffix(filepath.Base(path), ".json")
+	}
+	return names
+}()
+
+func loadPoolFixture(t testing.TB, name string) (*Pool, []Tick) {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "pools", name+".json"))
+	if err != nil {
+		t.Fatalf("loadPoolFixture(%s): %s", name, err)
+	}
+
+	var fixture poolFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("loadPoolFixture(%s): %s", name, err)
+	}
+
+	return &fixture.Pool, fixture.Ticks
+}
+
+type swapGolden struct {
+	ZeroForOne        bool
+	AmountSpecified   *big.Int
+	SqrtPriceLimitX96 *big.Int
+	Result            *SwapResult
+}
+
+// swapGoldenCases returns swaps in both directions, exact input and output, from a dust amount to
+// the amounts which exhaust the liquidity, plus the swaps stopped by the price limit
+func swapGoldenCases(pool *Pool) []swapGolden {
+	cases := make([]swapGolden, 0)
+
+	scales := []*big.Int{
+		big.NewInt(1),
+		big.NewInt(0).Div(pool.Liquidity.Val, big.NewInt(1e6)),
+		big.NewInt(0).Div(pool.Liquidity.Val, big.NewInt(100)),
+		big.NewInt(0).Mul(pool.Liquidity.Val, big.NewInt(10)),
+		big.NewInt(0).Mul(pool.Liquidity.Val, big.NewInt(1e6)),
+	}
+
+	spacing := pool.MustFeeTierToTickSpacing()
+	ticks := big.NewInt(0).Mul(spacing, big.NewInt(5))
+
+	for _, zeroForOne := range []bool{true, false} {
+		limitTick := big.NewInt(0).Add(pool.Tick.Val, ticks)
+		if zeroForOne {
+			limitTick.Sub(pool.Tick.Val, ticks)
+		}
+
+		// MIN_SQRT_RATIO itself is not a valid limit
+		if limitTick.Cmp(MIN_TICK) <= 0 {
+			limitTick.Add(MIN_TICK, ONE_UINT_256)
+		}
+
+		limits := []*big.Int{big.NewInt(0), GetSqrtRatioAtTick(limitTick)}
+
+		for _, limit := range limits {
+			for _, scale := range scales {
+				for _, sign := range []int64{1, -1} {
+					cases = append(cases, swapGolden{
+						ZeroForOne:        zeroForOne,
+						AmountSpecified:   big.NewInt(0).Mul(scale, big.NewInt(sign)),
+						SqrtPriceLimitX96: limit})
+				}
+			}
+		}
+	}
+
+	return cases
+}
+
+// loadSwapGolden reads testdata/golden/<name>.json written by TestDoSwapGolden
+func loadSwapGolden(t testing.TB, name string) []swapGolden {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name+".json")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%s): %s, run with -update to create the golden file", path, err)
+	}
+
+	var golden []swapGolden
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("json.Unmarshal(%s): %s", path, err)
+	}
+
+	return golden
+}
+
+func TestDoSwapGolden(t *testing.T) {
+	for _, name := range poolFixtures {
+		t.Run(name, func(t *testing.T) {
+			pool, ticks := loadPoolFixture(t, name)
+			// the swaps step through the words of the bitmap like the pool, so the goldens are comparable
+			// with the contract, see TestEVMSwapGolden
+			ticker := NewTickBitmap(NewTickStorage(ticks, pool.MustFeeTierToTickSpacing()))
+			cases := swapGoldenCases(pool)
+
+			for i := range cases {
+				c := &cases[i]
+
+				res, err := DoSwap(c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, ticker, pool)
+				if err != nil {
+					t.Fatalf("DoSwap(%t, %d, %d): %s", c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, err)
+				}
+
+				resU256, err := DoSwapU256(c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, ticker, pool)
+				if err != nil {
+					t.Fatalf("DoSwapU256(%t, %d, %d): %s", c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, err)
+				}
+
+				if !swapResultsEqual(resU256, res) {
+					t.Errorf("DoSwapU256(%t, %d, %d) = %+v; want %+v", c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, resU256, res)
+				}
+
+				c.Result = res
+			}
+
+			path := filepath.Join("testdata", "golden", name+".json")
+
+			if *updateGolden {
+				data, err := json.MarshalIndent(cases, "", "  ")
+				if err != nil {
+					t.Fatalf("json.MarshalIndent(): %s", err)
+				}
+
+				if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+					t.Fatalf("os.WriteFile(%s): %s", path, err)
+				}
+				return
+			}
+
+			golden := loadSwapGolden(t, name)
+			if len(golden) != len(cases) {
+				t.Fatalf("%s has %d cases; want %d", path, len(golden), len(cases))
+			}
+
+			for i, c := range cases {
+				want := golden[i]
+
+				if want.ZeroForOne != c.ZeroForOne ||
+					want.AmountSpecified.Cmp(c.AmountSpecified) != 0 ||
+					want.SqrtPriceLimitX96.Cmp(c.SqrtPriceLimitX96) != 0 {
+					t.Fatalf("%s case %d is (%t, %d, %d); want (%t, %d, %d)", path, i,
+						want.ZeroForOne, want.AmountSpecified, want.SqrtPriceLimitX96,
+						c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96)
+				}
+
+				if !swapResultsEqual(c.Result, want.Result) {
+					t.Errorf("DoSwap(%t, %d, %d) = %+v; want %+v", c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, c.Result, want.Result)
+				}
+			}
+		})
+	}
+}
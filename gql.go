@@ -3,18 +3,32 @@ package uniswap_core
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/machinebox/graphql"
 )
 
+// blockArgument returns the argument of the query of the entities at the block, or none for the latest block
+func blockArgument(blockNumber *big.Int) string {
+	if blockNumber == nil {
+		return ""
+	}
+	return fmt.Sprintf(", block: {number: %d}", blockNumber)
+}
+
 func GetTicks(client *graphql.Client, poolId string) ([]Tick, error) {
+	return GetTicksAtBlock(client, poolId, nil)
+}
+
+// GetTicksAtBlock loads the ticks of the pool as they were at the block, nil is the latest block
+func GetTicksAtBlock(client *graphql.Client, poolId string, blockNumber *big.Int) ([]Tick, error) {
 	numSkip := 0
 
 	res := make([]Tick, 0)
 
 	req := graphql.NewRequest(`
 	query get_ticks($num_skip: Int, $pool_id: ID!) {
-		ticks(skip: $num_skip, where: {pool: $pool_id}) {
+		ticks(skip: $num_skip, where: {pool: $pool_id}` + blockArgument(blockNumber) + `) {
 			tickIdx
 			liquidityGross
 			liquidityNet
@@ -50,10 +64,17 @@ func GetTicks(client *graphql.Client, poolId string) ([]Tick, error) {
 	return res, nil
 }
 
+// GetPool loads the pool from the subgraph. The subgraph does not index feeProtocol,
+// so Pool.FeeProtocol is never filled and the swaps take no protocol fee unless it is set, see Pool.FeeProtocol
 func GetPool(client *graphql.Client, poolId string) (*Pool, error) {
+	return GetPoolAtBlock(client, poolId, nil)
+}
+
+// GetPoolAtBlock loads the pool as it was at the block like GetPool, nil is the latest block
+func GetPoolAtBlock(client *graphql.Client, poolId string, blockNumber *big.Int) (*Pool, error) {
 	req := graphql.NewRequest(`
 		query get_pools($pool_id: ID!) {
-			pools(where: {id: $pool_id}) {
+			pools(where: {id: $pool_id}` + blockArgument(blockNumber) + `) {
 			tick
 			sqrtPrice
 			liquidity
@@ -1,44 +1,230 @@
 package uniswap_core
 
 import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"strconv"
+	"strings"
 )
 
+// BigInt is an integer of the subgraph, it is encoded as a decimal string.
+// JSON, text and SQL values are decoded from quoted or raw decimal and 0x-prefixed hex numbers,
+// null and empty strings are decoded as nil
 type BigInt struct {
 	Val *big.Int
 }
 
+// BigDecimal is a decimal of the subgraph, it is encoded as a decimal string
+// with as many digits as needed to restore the value.
+// JSON, text and SQL values are decoded like BigInt ones, with bigDecimalPrec bits of precision
 type BigDecimal struct {
 	Val *big.Float
 }
 
+// the precision of the decoded BigDecimal values, enough for the 18 decimals of the token amounts
+const bigDecimalPrec = 256
+
+// unquoteNumber returns the number of the JSON value, ok is false for null
+func unquoteNumber(data []byte) (text []byte, ok bool, err error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) == 0 {
+		return nil, false, errors.New("empty input")
+	}
+
+	if string(data) == "null" {
+		return nil, false, nil
+	}
+
+	if data[0] == '"' {
+		if len(data) < 2 || data[len(data)-1] != '"' {
+			return nil, false, fmt.Errorf("unterminated string %s", data)
+		}
+		data = data[1 : len(data)-1]
+	}
+
+	return data, true, nil
+}
+
+// sqlText returns the text of the SQL value, ok is false for NULL
+func sqlText(src interface{}) (text []byte, ok bool, err error) {
+	switch v := src.(type) {
+	case nil:
+		return nil, false, nil
+	case []byte:
+		return v, true, nil
+	case string:
+		return []byte(v), true, nil
+	case int64:
+		return strconv.AppendInt(nil, v, 10), true, nil
+	case float64:
+		return strconv.AppendFloat(nil, v, 'f', -1, 64), true, nil
+	}
+
+	return nil, false, fmt.Errorf("unsupported type %T", src)
+}
+
+func (bi BigInt) MarshalText() ([]byte, error) {
+	if bi.Val == nil {
+		return []byte{}, nil
+	}
+	return bi.Val.Append(nil, 10), nil
+}
+
+// UnmarshalText decodes a signed decimal or 0x-prefixed hex integer, empty text is decoded as nil
+func (bi *BigInt) UnmarshalText(text []byte) error {
+	str := strings.TrimSpace(string(text))
+
+	if str == "" {
+		bi.Val = nil
+		return nil
+	}
+
+	digits, base := str, 10
+	sign := ""
+
+	if digits[0] == '-' || digits[0] == '+' {
+		sign, digits = digits[:1], digits[1:]
+	}
+
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		digits, base = digits[2:], 16
+	}
+
+	val, ok := big.NewInt(0).SetString(digits, base)
+	if !ok || digits[0] == '-' || digits[0] == '+' {
+		return fmt.Errorf("BigInt: UnmarshalText: invalid integer %q", str)
+	}
+
+	if sign == "-" {
+		val.Neg(val)
+	}
+
+	bi.Val = val
+	return nil
+}
+
+// MarshalJSON encodes the value as a decimal string like the subgraph does, nil is encoded as null
+func (bi BigInt) MarshalJSON() ([]byte, error) {
+	if bi.Val == nil {
+		return []byte("null"), nil
+	}
+	return strconv.AppendQuote(nil, bi.Val.String()), nil
+}
+
 func (bi *BigInt) UnmarshalJSON(data []byte) error {
-	bi.Val = big.NewInt(0)
+	text, ok, err := unquoteNumber(data)
+	if err != nil {
+		return fmt.Errorf("BigInt: UnmarshalJSON: %w", err)
+	}
+
+	if !ok {
+		bi.Val = nil
+		return nil
+	}
 
-	var ok bool
-	strField := string(data[1 : len(data)-1])
-	bi.Val, ok = bi.Val.SetString(strField, 10)
+	return bi.UnmarshalText(text)
+}
+
+// Scan decodes the SQL value, e.g. of a NUMERIC column, NULL is decoded as nil
+func (bi *BigInt) Scan(src interface{}) error {
+	if v, ok := src.(float64); ok && v != math.Trunc(v) {
+		return fmt.Errorf("BigInt: Scan: %v is not an integer", v)
+	}
+
+	text, ok, err := sqlText(src)
+	if err != nil {
+		return fmt.Errorf("BigInt: Scan: %w", err)
+	}
 
 	if !ok {
-		return fmt.Errorf("BigInt: UnmarshalJSON: something goes wrong with field %s", strField)
+		bi.Val = nil
+		return nil
 	}
 
+	return bi.UnmarshalText(text)
+}
+
+// Value encodes the value as a decimal string, nil is encoded as NULL
+func (bi BigInt) Value() (driver.Value, error) {
+	if bi.Val == nil {
+		return nil, nil
+	}
+	return bi.Val.String(), nil
+}
+
+func (bi BigDecimal) MarshalText() ([]byte, error) {
+	if bi.Val == nil {
+		return []byte{}, nil
+	}
+	return bi.Val.Append(nil, 'f', -1), nil
+}
+
+// UnmarshalText decodes a decimal or 0x-prefixed hex number, empty text is decoded as nil
+func (bi *BigDecimal) UnmarshalText(text []byte) error {
+	str := strings.TrimSpace(string(text))
+
+	if str == "" {
+		bi.Val = nil
+		return nil
+	}
+
+	val, ok := big.NewFloat(0).SetPrec(bigDecimalPrec).SetString(str)
+	if !ok || val.IsInf() {
+		return fmt.Errorf("BigDecimal: UnmarshalText: invalid decimal %q", str)
+	}
+
+	bi.Val = val
 	return nil
 }
 
+// MarshalJSON encodes the value as a decimal string with as many digits as needed to restore it, nil is encoded as null
+func (bi BigDecimal) MarshalJSON() ([]byte, error) {
+	if bi.Val == nil {
+		return []byte("null"), nil
+	}
+	return strconv.AppendQuote(nil, bi.Val.Text('f', -1)), nil
+}
+
 func (bi *BigDecimal) UnmarshalJSON(data []byte) error {
-	bi.Val = big.NewFloat(0.0)
+	text, ok, err := unquoteNumber(data)
+	if err != nil {
+		return fmt.Errorf("BigDecimal: UnmarshalJSON: %w", err)
+	}
+
+	if !ok {
+		bi.Val = nil
+		return nil
+	}
+
+	return bi.UnmarshalText(text)
+}
 
-	var ok bool
-	strField := string(data[1 : len(data)-1])
-	bi.Val, ok = bi.Val.SetString(strField)
+// Scan decodes the SQL value, e.g. of a NUMERIC column, NULL is decoded as nil
+func (bi *BigDecimal) Scan(src interface{}) error {
+	text, ok, err := sqlText(src)
+	if err != nil {
+		return fmt.Errorf("BigDecimal: Scan: %w", err)
+	}
 
 	if !ok {
-		return fmt.Errorf("BigInt: UnmarshalJSON: something goes wrong with field %s", strField)
+		bi.Val = nil
+		return nil
 	}
 
-	return nil
+	return bi.UnmarshalText(text)
+}
+
+// Value encodes the value as a decimal string, nil is encoded as NULL
+func (bi BigDecimal) Value() (driver.Value, error) {
+	if bi.Val == nil {
+		return nil, nil
+	}
+	return bi.Val.Text('f', -1), nil
 }
 
 type FieldId struct {
@@ -117,6 +303,10 @@ type Swap struct {
 	LogIndex     BigInt
 }
 
+// Pool is the pool entity of the subgraph.
+// FeeProtocol is the feeProtocol of slot0, (feeProtocol1 << 4) + feeProtocol0. The subgraph does not index it,
+// so GetPool leaves it nil (zero) and it has to be set from slot0() of the pool contract
+// or come from the JSON of a snapshot which includes "feeProtocol"
 type Pool struct {
 	Id                           string
 	CreatedAtTimestamp           BigInt
@@ -147,35 +337,84 @@ type Pool struct {
 	TotalValueLockedUSD          BigDecimal
 	TotalValueLockedUSDUntracked BigDecimal
 	LiquidityProviderCount       BigInt
+	FeeProtocol                  BigInt
 	Swaps                        []Swap
 	Ticks                        []Tick
 }
 
-func (p Pool) FeerTierToTickSpacing() *big.Int {
+// FeeTierToTickSpacing returns the tick spacing the factory assigns to the fee tier of the pool,
+// ErrFeeTierUnknown if the fee tier is not enabled by the factory or ErrPoolInvalid if it is missing
+func (p Pool) FeeTierToTickSpacing() (*big.Int, error) {
+	if p.FeeTier.Val == nil {
+		return nil, fmt.Errorf("%w: gql: feeTier is missing", ErrPoolInvalid)
+	}
+
 	switch p.FeeTier.Val.Uint64() {
 	case 10000:
-		return big.NewInt(200)
+		return big.NewInt(200), nil
 	case 3000:
-		return big.NewInt(60)
+		return big.NewInt(60), nil
 	case 500:
-		return big.NewInt(10)
+		return big.NewInt(10), nil
 	case 100:
-		return big.NewInt(1)
+		return big.NewInt(1), nil
+	}
+
+	return nil, fmt.Errorf("%w: gql: Unexpected fee tier %d", ErrFeeTierUnknown, p.FeeTier.Val)
+}
+
+// MustFeeTierToTickSpacing is FeeTierToTickSpacing which panics with ErrFeeTierUnknown or ErrPoolInvalid
+func (p Pool) MustFeeTierToTickSpacing() *big.Int {
+	tickSpacing, err := p.FeeTierToTickSpacing()
+	if err != nil {
+		panic(err)
 	}
+	return tickSpacing
+}
 
-	panic(fmt.Errorf("gql: Unexpected fee tier %d", p.FeeTier.Val))
+// Deprecated: FeerTierToTickSpacing is the misspelled name of MustFeeTierToTickSpacing
+func (p Pool) FeerTierToTickSpacing() *big.Int {
+	return p.MustFeeTierToTickSpacing()
 }
 
-func (p Pool) CurrentState() *Slot0 {
+// CurrentState converts the pool into slot0, returns ErrFeeTierUnknown if the fee tier has no tick spacing
+// and ErrPoolInvalid if a field of the state is missing or null
+func (p Pool) CurrentState() (*Slot0, error) {
+	tickSpacing, err := p.FeeTierToTickSpacing()
+	if err != nil {
+		return nil, err
+	}
+
+	required := []struct {
+		name string
+		val  *big.Int
+	}{
+		{"tick", p.Tick.Val},
+		{"liquidity", p.Liquidity.Val},
+		{"sqrtPrice", p.SqrtPrice.Val},
+		{"feeGrowthGlobal0X128", p.FeeGrowthGlobal0X128.Val},
+		{"feeGrowthGlobal1X128", p.FeeGrowthGlobal1X128.Val},
+	}
+
+	for _, field := range required {
+		if field.val == nil {
+			return nil, fmt.Errorf("%w: gql: %s is missing", ErrPoolInvalid, field.name)
+		}
+	}
+
 	slot0 := NewSlot0()
-	slot0.TickSpacing.Set(p.FeerTierToTickSpacing())
+	slot0.TickSpacing.Set(tickSpacing)
 	slot0.TickCurrent.Set(p.Tick.Val)
 	slot0.Fee.Set(p.FeeTier.Val)
 	slot0.Liquidity.Set(p.Liquidity.Val)
 	slot0.FeeGrowthGlobal0X128.Set(p.FeeGrowthGlobal0X128.Val)
 	slot0.FeeGrowthGlobal1X128.Set(p.FeeGrowthGlobal1X128.Val)
 	slot0.SqrtPriceX96.Set(p.SqrtPrice.Val)
-	slot0.FeeProtocol.Set(big.NewInt(0))
 
-	return slot0
+	// the protocol fee is not indexed by the subgraph, it is known only if it was set explicitly
+	if p.FeeProtocol.Val != nil {
+		slot0.FeeProtocol.Set(p.FeeProtocol.Val)
+	}
+
+	return slot0, nil
 }
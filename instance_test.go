@@ -0,0 +1,277 @@
+package uniswap_core
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestPoolJSON(t *testing.T) {
+	pool, _ := loadPoolFixture(t, "usdc_weth_500")
+	pool.Token0Price = BigDecimal{Val: big.NewFloat(1855.125)}
+
+	data, err := json.Marshal(pool)
+	if err != nil {
+		t.Fatalf("json.Marshal(): %s", err)
+	}
+
+	var decoded Pool
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal(): %s", err)
+	}
+
+	if decoded.SqrtPrice.Val.Cmp(pool.SqrtPrice.Val) != 0 || decoded.FeeProtocol.Val.Cmp(pool.FeeProtocol.Val) != 0 {
+		t.Errorf("json.Unmarshal() = %d, %d; want %d, %d", decoded.SqrtPrice.Val, decoded.FeeProtocol.Val,
+			pool.SqrtPrice.Val, pool.FeeProtocol.Val)
+	}
+
+	if decoded.Token0Price.Val.Text('f', -1) != pool.Token0Price.Val.Text('f', -1) {
+		t.Errorf("json.Unmarshal() Token0Price = %s; want %s", decoded.Token0Price.Val.Text('f', -1), pool.Token0Price.Val.Text('f', -1))
+	}
+
+	if decoded.Token1Price.Val != nil || decoded.CreatedAtTimestamp.Val != nil {
+		t.Errorf("json.Unmarshal() = %v, %v; want nil, nil", decoded.Token1Price.Val, decoded.CreatedAtTimestamp.Val)
+	}
+}
+
+func TestPoolCurrentState(t *testing.T) {
+	pool, ticks := newTestPool()
+
+	slot0, err := pool.CurrentState()
+	if err != nil {
+		t.Fatalf("Pool.CurrentState(): %s", err)
+	}
+
+	if slot0.TickSpacing.Int64() != 60 || slot0.SqrtPriceX96.Cmp(pool.SqrtPrice.Val) != 0 {
+		t.Errorf("Pool.CurrentState() = %d, %d; want 60, %d", slot0.TickSpacing, slot0.SqrtPriceX96, pool.SqrtPrice.Val)
+	}
+
+	// the state of the pool with an unknown fee tier is not read with a zero tick spacing
+	pool.FeeTier = BigInt{Val: big.NewInt(42)}
+
+	if _, err := pool.FeeTierToTickSpacing(); !errors.Is(err, ErrFeeTierUnknown) {
+		t.Errorf("Pool.FeeTierToTickSpacing() error = %v; want %v", err, ErrFeeTierUnknown)
+	}
+
+	if _, err := pool.CurrentState(); !errors.Is(err, ErrFeeTierUnknown) {
+		t.Errorf("Pool.CurrentState() error = %v; want %v", err, ErrFeeTierUnknown)
+	}
+
+	ticker := NewTickStorage(ticks, big.NewInt(60))
+	if _, err := DoSwap(true, big.NewInt(1000), big.NewInt(0), ticker, pool); !errors.Is(err, ErrFeeTierUnknown) {
+		t.Errorf("DoSwap() error = %v; want %v", err, ErrFeeTierUnknown)
+	}
+
+	if _, err := NewPoolSimulator(pool, ticker); !errors.Is(err, ErrFeeTierUnknown) {
+		t.Errorf("NewPoolSimulator() error = %v; want %v", err, ErrFeeTierUnknown)
+	}
+}
+
+func TestPoolCurrentStateMissing(t *testing.T) {
+	// the subgraph responses with the missing or null fields are rejected instead of dereferencing nil
+	cases := []string{
+		`{"feeTier": "3000", "tick": null, "sqrtPrice": "79228162514264337593543950336", "liquidity": "1",
+			"feeGrowthGlobal0X128": "0", "feeGrowthGlobal1X128": "0"}`,
+		`{"feeTier": "3000", "tick": "0", "sqrtPrice": "79228162514264337593543950336", "liquidity": "1",
+			"feeGrowthGlobal0X128": "0"}`,
+		`{"tick": "0", "sqrtPrice": "79228162514264337593543950336", "liquidity": "1",
+			"feeGrowthGlobal0X128": "0", "feeGrowthGlobal1X128": "0"}`,
+	}
+
+	for _, c := range cases {
+		var pool Pool
+		if err := json.Unmarshal([]byte(c), &pool); err != nil {
+			t.Fatalf("json.Unmarshal(%s): %s", c, err)
+		}
+
+		if _, err := pool.CurrentState(); !errors.Is(err, ErrPoolInvalid) {
+			t.Errorf("Pool.CurrentState() of %s error = %v; want %v", c, err, ErrPoolInvalid)
+		}
+
+		if _, err := NewPoolSimulator(pool, NewTickStorage(nil, big.NewInt(60))); !errors.Is(err, ErrPoolInvalid) {
+			t.Errorf("NewPoolSimulator() of %s error = %v; want %v", c, err, ErrPoolInvalid)
+		}
+	}
+
+	var pool Pool
+	if err := json.Unmarshal([]byte(`{"feeTier": null}`), &pool); err != nil {
+		t.Fatalf("json.Unmarshal(): %s", err)
+	}
+
+	if _, err := pool.FeeTierToTickSpacing(); !errors.Is(err, ErrPoolInvalid) {
+		t.Errorf("Pool.FeeTierToTickSpacing() error = %v; want %v", err, ErrPoolInvalid)
+	}
+}
+
+func TestBigIntUnmarshalJSON(t *testing.T) {
+	maxUint256 := MAX_UINT_256.String()
+
+	cases := []struct {
+		data string
+		want *big.Int
+	}{
+		{`"123"`, big.NewInt(123)},
+		{`123`, big.NewInt(123)},
+		{`"-887272"`, big.NewInt(-887272)},
+		{` -887272 `, big.NewInt(-887272)},
+		{`"0xff"`, big.NewInt(255)},
+		{`"-0XFF"`, big.NewInt(-255)},
+		{`"` + maxUint256 + `"`, MAX_UINT_256},
+		{maxUint256, MAX_UINT_256},
+		{`"0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"`, MAX_UINT_256},
+		{`null`, nil},
+		{`""`, nil},
+	}
+
+	for _, c := range cases {
+		bi := BigInt{Val: big.NewInt(1)}
+		if err := bi.UnmarshalJSON([]byte(c.data)); err != nil {
+			t.Errorf("BigInt.UnmarshalJSON(%s): %s", c.data, err)
+			continue
+		}
+
+		if c.want == nil && bi.Val != nil || c.want != nil && (bi.Val == nil || bi.Val.Cmp(c.want) != 0) {
+			t.Errorf("BigInt.UnmarshalJSON(%s) = %v; want %v", c.data, bi.Val, c.want)
+		}
+	}
+
+	for _, data := range []string{``, `"`, `"12`, `"1.5"`, `"0x"`, `"-"`, `"0x-5"`, `"--5"`, `"1_000"`, `abc`, `true`} {
+		var bi BigInt
+		if err := bi.UnmarshalJSON([]byte(data)); err == nil {
+			t.Errorf("BigInt.UnmarshalJSON(%s) = %v; want error", data, bi.Val)
+		}
+	}
+}
+
+func TestBigDecimalUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		data string
+		want string
+	}{
+		{`"1855.125"`, "1855.125"},
+		{`1855.125`, "1855.125"},
+		{`"0.000000000000000001"`, "0.000000000000000001"},
+		{`"123456789012345678901234567890.123456789012345678"`, "123456789012345678901234567890.123456789012345678"},
+		{`"-1e3"`, "-1000"},
+		{`"0x10"`, "16"},
+	}
+
+	for _, c := range cases {
+		var bd BigDecimal
+		if err := bd.UnmarshalJSON([]byte(c.data)); err != nil {
+			t.Errorf("BigDecimal.UnmarshalJSON(%s): %s", c.data, err)
+			continue
+		}
+
+		if text, _ := bd.MarshalText(); string(text) != c.want {
+			t.Errorf("BigDecimal.UnmarshalJSON(%s) = %s; want %s", c.data, text, c.want)
+		}
+	}
+
+	bd := BigDecimal{Val: big.NewFloat(1)}
+	if err := bd.UnmarshalJSON([]byte(`null`)); err != nil || bd.Val != nil {
+		t.Errorf("BigDecimal.UnmarshalJSON(null) = %v, %v; want nil, nil", bd.Val, err)
+	}
+
+	for _, data := range []string{``, `"`, `"1.2.3"`, `"Inf"`, `abc`} {
+		var bd BigDecimal
+		if err := bd.UnmarshalJSON([]byte(data)); err == nil {
+			t.Errorf("BigDecimal.UnmarshalJSON(%s) = %v; want error", data, bd.Val)
+		}
+	}
+}
+
+func TestBigIntText(t *testing.T) {
+	type record struct {
+		Amount BigInt
+		Price  BigDecimal
+		Fee    BigInt
+	}
+
+	// the text encoding is used for the map keys
+	keys, err := json.Marshal(map[BigInt]string{{Val: big.NewInt(-60)}: "lower"})
+	if err != nil || string(keys) != `{"-60":"lower"}` {
+		t.Errorf("json.Marshal(map[BigInt]string) = %s, %v; want {\"-60\":\"lower\"}, nil", keys, err)
+	}
+
+	src := record{Amount: BigInt{Val: MAX_UINT_256}, Price: BigDecimal{Val: big.NewFloat(0.5)}}
+
+	data, err := json.Marshal(src)
+	if err != nil {
+		t.Fatalf("json.Marshal(): %s", err)
+	}
+
+	want := `{"Amount":"` + MAX_UINT_256.String() + `","Price":"0.5","Fee":null}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s; want %s", data, want)
+	}
+
+	text, err := src.Amount.MarshalText()
+	if err != nil || string(text) != MAX_UINT_256.String() {
+		t.Errorf("BigInt.MarshalText() = %s, %v; want %d, nil", text, err, MAX_UINT_256)
+	}
+
+	var dst BigInt
+	if err := dst.UnmarshalText(text); err != nil || dst.Val.Cmp(MAX_UINT_256) != 0 {
+		t.Errorf("BigInt.UnmarshalText(%s) = %d, %v; want %d, nil", text, dst.Val, err, MAX_UINT_256)
+	}
+}
+
+func TestBigIntSQL(t *testing.T) {
+	cases := []struct {
+		src  interface{}
+		want *big.Int
+	}{
+		{[]byte("340282366920938463463374607431768211455"), MAX_UINT_128},
+		{"-887272", big.NewInt(-887272)},
+		{int64(3000), big.NewInt(3000)},
+		{float64(1e20), big.NewInt(0).Exp(big.NewInt(10), big.NewInt(20), nil)},
+		{nil, nil},
+	}
+
+	for _, c := range cases {
+		var bi BigInt
+		if err := bi.Scan(c.src); err != nil {
+			t.Errorf("BigInt.Scan(%v): %s", c.src, err)
+			continue
+		}
+
+		if c.want == nil && bi.Val != nil || c.want != nil && (bi.Val == nil || bi.Val.Cmp(c.want) != 0) {
+			t.Errorf("BigInt.Scan(%v) = %v; want %v", c.src, bi.Val, c.want)
+		}
+
+		value, err := bi.Value()
+		if err != nil {
+			t.Errorf("BigInt.Value(): %s", err)
+		}
+
+		if c.want == nil && value != nil || c.want != nil && value != c.want.String() {
+			t.Errorf("BigInt.Value() = %v; want %v", value, c.want)
+		}
+	}
+
+	for _, src := range []interface{}{1.5, true, "1.5"} {
+		var bi BigInt
+		if err := bi.Scan(src); err == nil {
+			t.Errorf("BigInt.Scan(%v) = %v; want error", src, bi.Val)
+		}
+	}
+
+	var bd BigDecimal
+	if err := bd.Scan([]byte("1234.000000000000000001")); err != nil {
+		t.Fatalf("BigDecimal.Scan(): %s", err)
+	}
+
+	if value, err := bd.Value(); err != nil || value != "1234.000000000000000001" {
+		t.Errorf("BigDecimal.Value() = %v, %v; want 1234.000000000000000001, nil", value, err)
+	}
+
+	if err := bd.Scan(float64(0.25)); err != nil || bd.Val.Cmp(big.NewFloat(0.25)) != 0 {
+		t.Errorf("BigDecimal.Scan(0.25) = %v, %v; want 0.25, nil", bd.Val, err)
+	}
+
+	if err := bd.Scan(nil); err != nil || bd.Val != nil {
+		t.Errorf("BigDecimal.Scan(nil) = %v, %v; want nil, nil", bd.Val, err)
+	}
+}
@@ -1,20 +1,50 @@
 package uniswap_core
 
 import (
+	"fmt"
 	"math/big"
 )
 
+// The strict argument of the Try functions enforces the bit widths and the revert conditions of the contracts:
+// FullMath overflow, 'LA'/'LS' of LiquidityMath, SafeCast and uint160/uint128 casts, so the simulations fail
+// with ErrOverflow or ErrInsufficientLiquidity exactly where the contract reverts and wrap where the contract wraps.
+// Otherwise the values are unbounded like in the functions which panic. See DoSwapStrict and PoolSimulator.Strict
+
+// Panics if the denominator is zero, see TryMulDiv
 func MulDiv(
 	a *big.Int,
 	b *big.Int,
 	denominator *big.Int) (result *big.Int) {
+	result, err := TryMulDiv(a, b, denominator, false)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// TryMulDiv calculates floor(a * b / denominator) with full precision,
+// returns ErrOverflow if the denominator is zero or, if strict, the result overflows uint256
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/FullMath.sol
+func TryMulDiv(
+	a *big.Int,
+	b *big.Int,
+	denominator *big.Int,
+	strict bool) (result *big.Int, err error) {
+	if denominator.Sign() == 0 {
+		return nil, fmt.Errorf("%w: math: division of %d * %d by zero", ErrOverflow, a, b)
+	}
+
 	result = big.NewInt(0)
 	result.Mul(a, b)
 	result.Div(result, denominator)
+
+	if strict && result.Cmp(MAX_UINT_256) > 0 {
+		return nil, fmt.Errorf("%w: math: %d * %d / %d exceeds uint256", ErrOverflow, a, b, denominator)
+	}
 	return
 }
 
-// Deprecated: Obtain [a * b / denominator]
+// Deprecated: use TryMulDiv with strict. Obtain [a * b / denominator]
 // Source: https://xn--2-umb.com/21/muldiv/index.html
 // Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/FullMath.sol
 func MulDivChn(
@@ -81,12 +111,29 @@ func MulDivChn(
 	return
 }
 
-// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/FullMath.sol
+// Panics on the same conditions as MulDiv, see TryMulDivRoundingUp
 func MulDivRoundingUp(
 	a *big.Int,
 	b *big.Int,
 	denominator *big.Int) (result *big.Int) {
-	result = MulDiv(a, b, denominator)
+	result, err := TryMulDivRoundingUp(a, b, denominator, false)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// TryMulDivRoundingUp calculates ceil(a * b / denominator) with full precision,
+// returns ErrOverflow on the same conditions as TryMulDiv
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/FullMath.sol
+func TryMulDivRoundingUp(
+	a *big.Int,
+	b *big.Int,
+	denominator *big.Int,
+	strict bool) (result *big.Int, err error) {
+	if result, err = TryMulDiv(a, b, denominator, strict); err != nil {
+		return
+	}
 
 	prod0 := big.NewInt(0)
 
@@ -94,6 +141,9 @@ func MulDivRoundingUp(
 	prod0.Mod(prod0, denominator)
 
 	if prod0.Cmp(ZERO_UINT_256) > 0 {
+		if strict && result.Cmp(MAX_UINT_256) >= 0 {
+			return nil, fmt.Errorf("%w: math: %d * %d / %d rounded up exceeds uint256", ErrOverflow, a, b, denominator)
+		}
 		result.Add(result, ONE_UINT_256)
 	}
 	return
@@ -116,8 +166,42 @@ func DivRoundingUp(
 	return
 }
 
+// Add a signed liquidity delta to liquidity, the result is unbounded, see TryAddLiquidityDelta
 func AddLiquidityDelta(x *big.Int, y *big.Int) (z *big.Int) {
+	z, err := TryAddLiquidityDelta(x, y, false)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// Add a signed liquidity delta to liquidity, if strict the result must fit uint128:
+// ErrOverflow ('LA') if it overflows, ErrInsufficientLiquidity ('LS') if it underflows
+// x	big.Int	uint128 liquidity before the change
+// y	big.Int	int128 delta by which liquidity should be changed
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/LiquidityMath.sol
+func TryAddLiquidityDelta(x *big.Int, y *big.Int, strict bool) (z *big.Int, err error) {
 	z = big.NewInt(0)
 	z.Add(x, y)
+
+	if !strict {
+		return
+	}
+
+	if z.Sign() < 0 {
+		return nil, fmt.Errorf("%w: math: liquidity %d less than %d", ErrInsufficientLiquidity, x, big.NewInt(0).Neg(y))
+	}
+
+	if z.Cmp(MAX_UINT_128) > 0 {
+		return nil, fmt.Errorf("%w: math: liquidity %d + %d exceeds uint128", ErrOverflow, x, y)
+	}
+	return
+}
+
+// Emulates unchecked uint256 subtraction, i.e. (a - b) mod 2**256
+func SubUint256(a *big.Int, b *big.Int) (z *big.Int) {
+	z = big.NewInt(0)
+	z.Sub(a, b)
+	z.And(z, MAX_UINT_256)
 	return
 }
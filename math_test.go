@@ -1,6 +1,7 @@
 package uniswap_core
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 )
@@ -88,3 +89,69 @@ func TestDivRoundingUp(t *testing.T) {
 		t.Errorf("DivRoundingUp(%d, %d) = %d; want %d", a, b, res, org)
 	}
 }
+
+func TestSubUint256(t *testing.T) {
+	res := SubUint256(big.NewInt(5), big.NewInt(3))
+	if res.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("SubUint256(5, 3) = %d; want %d", res, 2)
+	}
+
+	res = SubUint256(big.NewInt(3), big.NewInt(5))
+	ref := big.NewInt(0).Sub(MAX_UINT_256, big.NewInt(1))
+	if res.Cmp(ref) != 0 {
+		t.Errorf("SubUint256(3, 5) = %d; want %d", res, ref)
+	}
+}
+
+func TestTryMulDivStrict(t *testing.T) {
+	two := big.NewInt(2)
+
+	if res, err := TryMulDiv(MAX_UINT_256, two, ONE_UINT_256, false); err != nil || res.Cmp(big.NewInt(0).Mul(MAX_UINT_256, two)) != 0 {
+		t.Errorf("TryMulDiv(MAX_UINT_256, 2, 1) = %d, %v; want unbounded result", res, err)
+	}
+
+	if _, err := TryMulDiv(ONE_UINT_256, two, ZERO_UINT_256, false); !errors.Is(err, ErrOverflow) {
+		t.Errorf("TryMulDiv(1, 2, 0) error = %v; want %v", err, ErrOverflow)
+	}
+
+	// the bit widths of the contract
+	if _, err := TryMulDiv(MAX_UINT_256, two, ONE_UINT_256, true); !errors.Is(err, ErrOverflow) {
+		t.Errorf("TryMulDiv(MAX_UINT_256, 2, 1) error = %v; want %v", err, ErrOverflow)
+	}
+
+	// the intermediate product may exceed uint256
+	if res, err := TryMulDiv(MAX_UINT_256, two, two, true); err != nil || res.Cmp(MAX_UINT_256) != 0 {
+		t.Errorf("TryMulDiv(MAX_UINT_256, 2, 2) = %d, %v; want %d", res, err, MAX_UINT_256)
+	}
+
+	maxSubOne := big.NewInt(0).Sub(MAX_UINT_256, ONE_UINT_256)
+	if res, err := TryMulDivRoundingUp(MAX_UINT_256, maxSubOne, MAX_UINT_256, true); err != nil || res.Cmp(maxSubOne) != 0 {
+		t.Errorf("TryMulDivRoundingUp(MAX_UINT_256, MAX_UINT_256 - 1, MAX_UINT_256) = %d, %v; want %d", res, err, maxSubOne)
+	}
+
+	// floor(a * b / 2) = MAX_UINT_256 with a remainder, the result overflows after rounding up
+	a := big.NewInt(535006138814359)
+	b, _ := big.NewInt(0).SetString("432862656469423142931042426214547535783388063929571229938474969", 10)
+	if _, err := TryMulDivRoundingUp(a, b, two, true); !errors.Is(err, ErrOverflow) {
+		t.Errorf("TryMulDivRoundingUp(%d, %d, 2) error = %v; want %v", a, b, err, ErrOverflow)
+	}
+}
+
+func TestTryAddLiquidityDelta(t *testing.T) {
+	if res, err := TryAddLiquidityDelta(ONE_UINT_256, big.NewInt(-2), false); err != nil || res.Cmp(big.NewInt(-1)) != 0 {
+		t.Errorf("TryAddLiquidityDelta(1, -2) = %d, %v; want -1", res, err)
+	}
+
+	// the bit widths of the contract
+	if res, err := TryAddLiquidityDelta(big.NewInt(3), big.NewInt(-2), true); err != nil || res.Cmp(ONE_UINT_256) != 0 {
+		t.Errorf("TryAddLiquidityDelta(3, -2) = %d, %v; want 1", res, err)
+	}
+
+	if _, err := TryAddLiquidityDelta(ONE_UINT_256, big.NewInt(-2), true); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("TryAddLiquidityDelta(1, -2) error = %v; want %v", err, ErrInsufficientLiquidity)
+	}
+
+	if _, err := TryAddLiquidityDelta(MAX_UINT_128, ONE_UINT_256, true); !errors.Is(err, ErrOverflow) {
+		t.Errorf("TryAddLiquidityDelta(MAX_UINT_128, 1) error = %v; want %v", err, ErrOverflow)
+	}
+}
@@ -0,0 +1,115 @@
+package uniswap_core
+
+import (
+	"math/big"
+
+	"github.com/holiman/uint256"
+)
+
+// Fixed-width counterparts of the constants used by the swap math
+var (
+	q96U256          uint256.Int
+	q128U256         uint256.Int
+	maxUint128U256   uint256.Int
+	maxUint160U256   uint256.Int
+	maxUint256U256   uint256.Int
+	minSqrtRatioU256 uint256.Int
+	maxSqrtRatioU256 uint256.Int
+)
+
+func init() {
+	q96U256.Lsh(uint256.NewInt(1), 96)
+	q128U256.Lsh(uint256.NewInt(1), 128)
+	maxUint128U256.Lsh(uint256.NewInt(1), 128)
+	maxUint128U256.SubUint64(&maxUint128U256, 1)
+	maxUint160U256.Lsh(uint256.NewInt(1), 160)
+	maxUint160U256.SubUint64(&maxUint160U256, 1)
+	maxUint256U256.SetAllOne()
+	minSqrtRatioU256.SetUint64(4295128739)
+	maxSqrtRatio, _ := uint256.FromHex("0xFFFD8963EFD1FC6A506488495D951D5263988D26")
+	maxSqrtRatioU256.Set(maxSqrtRatio)
+}
+
+// Converts the int256 two's complement value to big.Int
+func u256ToBigSigned(x *uint256.Int) *big.Int {
+	if x.Sign() < 0 {
+		var abs uint256.Int
+		abs.Neg(x)
+		res := abs.ToBig()
+		return res.Neg(res)
+	}
+	return x.ToBig()
+}
+
+// Converts the signed big.Int to int256 two's complement value, returns true if the value is out of int256
+func u256FromBigSigned(z *uint256.Int, x *big.Int) (overflow bool) {
+	if x.Cmp(MIN_INT_256) < 0 || x.Cmp(MAX_INT_256) > 0 {
+		return true
+	}
+	z.SetFromBig(x)
+	return false
+}
+
+// Checked int256 arithmetic of the swap loop, z = x + y or z = x - y of the int256 two's complement x
+// and the uint256 y which is cast with SafeCast.toInt256, returns true if y or the result is out of int256
+func addInt256U256(z *uint256.Int, x *uint256.Int, y *uint256.Int, sub bool) (overflow bool) {
+	if y.Sign() < 0 {
+		return true
+	}
+
+	negative := x.Sign() < 0
+	if sub {
+		z.Sub(x, y)
+		return negative && z.Sign() >= 0
+	}
+
+	z.Add(x, y)
+	return !negative && z.Sign() < 0
+}
+
+// Fixed-width MulDiv, z = a * b / denominator with the full precision of the 512-bit product,
+// returns true if the result does not fit into 256 bits or the denominator is zero
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/FullMath.sol
+func MulDivU256(z *uint256.Int, a *uint256.Int, b *uint256.Int, denominator *uint256.Int) (overflow bool) {
+	if denominator.IsZero() {
+		z.Clear()
+		return true
+	}
+
+	_, overflow = z.MulDivOverflow(a, b, denominator)
+	return
+}
+
+// Fixed-width MulDivRoundingUp, z = ceil(a * b / denominator)
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/FullMath.sol
+func MulDivRoundingUpU256(z *uint256.Int, a *uint256.Int, b *uint256.Int, denominator *uint256.Int) (overflow bool) {
+	var rem uint256.Int
+	rem.MulMod(a, b, denominator)
+
+	if MulDivU256(z, a, b, denominator) {
+		return true
+	}
+
+	if !rem.IsZero() {
+		if z.Eq(&maxUint256U256) {
+			return true
+		}
+		z.AddUint64(z, 1)
+	}
+
+	return false
+}
+
+// Fixed-width DivRoundingUp, z = ceil(a / b), b must be positive
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/UnsafeMath.sol
+func DivRoundingUpU256(z *uint256.Int, a *uint256.Int, b *uint256.Int) *uint256.Int {
+	var rem uint256.Int
+	rem.Mod(a, b)
+	z.Div(a, b)
+
+	if !rem.IsZero() {
+		z.AddUint64(z, 1)
+	}
+
+	return z
+}
@@ -39,6 +39,72 @@ func NewSlot0() *Slot0 {
 	return s
 }
 
+// Copy returns a deep copy of the slot, so it may be changed without affecting the original
+func (s *Slot0) Copy() *Slot0 {
+	c := NewSlot0()
+	c.TickSpacing.Set(s.TickSpacing)
+	c.TickCurrent.Set(s.TickCurrent)
+	c.Fee.Set(s.Fee)
+	c.Liquidity.Set(s.Liquidity)
+	c.FeeGrowthGlobal0X128.Set(s.FeeGrowthGlobal0X128)
+	c.FeeGrowthGlobal1X128.Set(s.FeeGrowthGlobal1X128)
+	c.SqrtPriceX96.Set(s.SqrtPriceX96)
+	c.ObservationIndex.Set(s.ObservationIndex)
+	c.ObservationCardinality.Set(s.ObservationCardinality)
+	c.ObservationCardinalityNext.Set(s.ObservationCardinalityNext)
+	c.FeeProtocol.Set(s.FeeProtocol)
+	return c
+}
+
+// CurrentState implements PoolStateReader, so the slot itself may be passed where the pool is read
+func (s *Slot0) CurrentState() (*Slot0, error) {
+	return s, nil
+}
+
+// Oracle observation of the pool
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/Oracle.sol
+type Observation struct {
+	// the block timestamp of the observation
+	BlockTimestamp *big.Int
+	// the tick accumulator, i.e. tick * time elapsed since the pool was first initialized
+	TickCumulative *big.Int
+	// the seconds per liquidity, i.e. seconds elapsed / max(1, liquidity) since the pool was first initialized
+	SecondsPerLiquidityCumulativeX128 *big.Int
+	// whether or not the observation is initialized
+	Initialized bool
+}
+
+// Copy returns a deep copy of the observation
+func (o *Observation) Copy() Observation {
+	return Observation{
+		BlockTimestamp:                    big.NewInt(0).Set(o.BlockTimestamp),
+		TickCumulative:                    big.NewInt(0).Set(o.TickCumulative),
+		SecondsPerLiquidityCumulativeX128: big.NewInt(0).Set(o.SecondsPerLiquidityCumulativeX128),
+		Initialized:                       o.Initialized}
+}
+
+func copyObservations(observations []Observation) []Observation {
+	c := make([]Observation, len(observations))
+	for i := range observations {
+		c[i] = observations[i].Copy()
+	}
+	return c
+}
+
+// accumulated protocol fees in token0/token1 units
+type ProtocolFees struct {
+	Token0 *big.Int
+	Token1 *big.Int
+}
+
+func NewProtocolFees() *ProtocolFees {
+	return &ProtocolFees{
+		Token0: big.NewInt(0),
+		Token1: big.NewInt(0)}
+}
+
+// PoolStateReader returns the state of the pool, the error is returned if the state is incomplete,
+// e.g. the tick spacing of the pool is unknown
 type PoolStateReader interface {
-	CurrentState() *Slot0
+	CurrentState() (*Slot0, error)
 }
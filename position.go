@@ -0,0 +1,254 @@
+package uniswap_core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// info stored for each user's position
+type Position struct {
+	// the address of the position's owner
+	Owner string
+	// the lower tick boundary of the position
+	TickLower *big.Int
+	// the upper tick boundary of the position
+	TickUpper *big.Int
+	// the amount of liquidity owned by this position
+	Liquidity *big.Int
+	// fee growth per unit of liquidity as of the last update to liquidity or fees owed
+	FeeGrowthInside0LastX128 *big.Int
+	FeeGrowthInside1LastX128 *big.Int
+	// the fees owed to the position owner in token0/token1
+	TokensOwed0 *big.Int
+	TokensOwed1 *big.Int
+}
+
+func NewPosition(owner string, tickLower *big.Int, tickUpper *big.Int) *Position {
+	return &Position{
+		Owner:                    owner,
+		TickLower:                big.NewInt(0).Set(tickLower),
+		TickUpper:                big.NewInt(0).Set(tickUpper),
+		Liquidity:                big.NewInt(0),
+		FeeGrowthInside0LastX128: big.NewInt(0),
+		FeeGrowthInside1LastX128: big.NewInt(0),
+		TokensOwed0:              big.NewInt(0),
+		TokensOwed1:              big.NewInt(0)}
+}
+
+// Copy returns a deep copy of the position
+func (pos *Position) Copy() *Position {
+	c := NewPosition(pos.Owner, pos.TickLower, pos.TickUpper)
+	c.Liquidity.Set(pos.Liquidity)
+	c.FeeGrowthInside0LastX128.Set(pos.FeeGrowthInside0LastX128)
+	c.FeeGrowthInside1LastX128.Set(pos.FeeGrowthInside1LastX128)
+	c.TokensOwed0.Set(pos.TokensOwed0)
+	c.TokensOwed1.Set(pos.TokensOwed1)
+	return c
+}
+
+// Credits accumulated fees to a user's position
+// liquidityDelta	big.Int	The change in pool liquidity as a result of the position update
+// feeGrowthInside0X128	big.Int	The all-time fee growth in token0, per unit of liquidity, inside the position's tick boundaries
+// feeGrowthInside1X128	big.Int	The all-time fee growth in token1, per unit of liquidity, inside the position's tick boundaries
+// strict	bool	Whether the tokens owed wrap like the uint128 of the contract
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/Position.sol
+func (pos *Position) update(liquidityDelta *big.Int, feeGrowthInside0X128 *big.Int, feeGrowthInside1X128 *big.Int, strict bool) {
+	// calculate accumulated fees
+	tokensOwed0 := MulDiv(SubUint256(feeGrowthInside0X128, pos.FeeGrowthInside0LastX128), pos.Liquidity, Q128)
+	tokensOwed1 := MulDiv(SubUint256(feeGrowthInside1X128, pos.FeeGrowthInside1LastX128), pos.Liquidity, Q128)
+
+	// update the position
+	if liquidityDelta.Cmp(ZERO_UINT_256) != 0 {
+		pos.Liquidity = AddLiquidityDelta(pos.Liquidity, liquidityDelta)
+	}
+
+	pos.FeeGrowthInside0LastX128.Set(feeGrowthInside0X128)
+	pos.FeeGrowthInside1LastX128.Set(feeGrowthInside1X128)
+
+	// overflow is acceptable, have to withdraw before you hit type(uint128).max fees
+	wrapUint128(pos.TokensOwed0.Add(pos.TokensOwed0, wrapUint128(tokensOwed0, strict)), strict)
+	wrapUint128(pos.TokensOwed1.Add(pos.TokensOwed1, wrapUint128(tokensOwed1, strict)), strict)
+}
+
+type positionKey struct {
+	owner     string
+	tickLower int64
+	tickUpper int64
+}
+
+func newPositionKey(owner string, tickLower *big.Int, tickUpper *big.Int) positionKey {
+	return positionKey{owner: owner, tickLower: tickLower.Int64(), tickUpper: tickUpper.Int64()}
+}
+
+// PositionStore keeps positions keyed by owner and tick boundaries like the positions mapping of the pool
+type PositionStore struct {
+	positions map[positionKey]*Position
+	// the keys of the positions which are not shared with a fork
+	owned map[positionKey]bool
+}
+
+func NewPositionStore() *PositionStore {
+	return &PositionStore{
+		positions: make(map[positionKey]*Position),
+		owned:     make(map[positionKey]bool)}
+}
+
+// Fork returns a copy-on-write view of the store, the positions are shared until either store changes them.
+// Only the map of the positions is copied
+func (s *PositionStore) Fork() *PositionStore {
+	f := &PositionStore{
+		positions: make(map[positionKey]*Position, len(s.positions)),
+		owned:     make(map[positionKey]bool)}
+
+	for key, pos := range s.positions {
+		f.positions[key] = pos
+	}
+
+	// the positions owned so far are shared now
+	s.owned = make(map[positionKey]bool)
+	return f
+}
+
+// Get returns the position of the owner with the given boundaries or nil if there is no such position.
+// The position may be shared with the forks of the store, so it must not be changed
+func (s *PositionStore) Get(owner string, tickLower *big.Int, tickUpper *big.Int) *Position {
+	return s.positions[newPositionKey(owner, tickLower, tickUpper)]
+}
+
+// mutable returns the position which may be changed, copying it if it is shared with a fork,
+// nil if there is no such position
+func (s *PositionStore) mutable(owner string, tickLower *big.Int, tickUpper *big.Int) *Position {
+	key := newPositionKey(owner, tickLower, tickUpper)
+
+	pos, ok := s.positions[key]
+	if !ok {
+		return nil
+	}
+
+	if !s.owned[key] {
+		pos = pos.Copy()
+		s.Put(pos)
+	}
+	return pos
+}
+
+// getOrCreate returns the position which may be changed or an empty one which is not stored until Put is called
+func (s *PositionStore) getOrCreate(owner string, tickLower *big.Int, tickUpper *big.Int) *Position {
+	if pos := s.mutable(owner, tickLower, tickUpper); pos != nil {
+		return pos
+	}
+	return NewPosition(owner, tickLower, tickUpper)
+}
+
+// Put stores the position replacing the one with the same owner and boundaries, the store owns the position
+func (s *PositionStore) Put(pos *Position) {
+	key := newPositionKey(pos.Owner, pos.TickLower, pos.TickUpper)
+
+	if s.owned == nil {
+		s.owned = make(map[positionKey]bool)
+	}
+
+	s.positions[key] = pos
+	s.owned[key] = true
+}
+
+func (s *PositionStore) Len() int {
+	return len(s.positions)
+}
+
+// All returns all positions ordered by owner, lower and upper tick
+func (s *PositionStore) All() []*Position {
+	return s.filter(func(*Position) bool { return true })
+}
+
+// ByOwner returns positions of the owner ordered by lower and upper tick
+func (s *PositionStore) ByOwner(owner string) []*Position {
+	return s.filter(func(pos *Position) bool { return pos.Owner == owner })
+}
+
+// ByRange returns positions with the given boundaries ordered by owner
+func (s *PositionStore) ByRange(tickLower *big.Int, tickUpper *big.Int) []*Position {
+	return s.filter(func(pos *Position) bool {
+		return pos.TickLower.Cmp(tickLower) == 0 && pos.TickUpper.Cmp(tickUpper) == 0
+	})
+}
+
+func (s *PositionStore) filter(accept func(*Position) bool) []*Position {
+	res := make([]*Position, 0)
+
+	for _, pos := range s.positions {
+		if accept(pos) {
+			res = append(res, pos)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Owner != res[j].Owner {
+			return res[i].Owner < res[j].Owner
+		}
+		if c := res[i].TickLower.Cmp(res[j].TickLower); c != 0 {
+			return c < 0
+		}
+		return res[i].TickUpper.Cmp(res[j].TickUpper) < 0
+	})
+
+	return res
+}
+
+// MarshalJSON encodes the store as a list of positions ordered like All does
+func (s *PositionStore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.All())
+}
+
+func (s *PositionStore) UnmarshalJSON(data []byte) error {
+	var positions []*Position
+
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return err
+	}
+
+	s.positions = make(map[positionKey]*Position)
+	s.owned = make(map[positionKey]bool)
+
+	for _, pos := range positions {
+		if pos.TickLower == nil || pos.TickUpper == nil {
+			return fmt.Errorf("PositionStore: UnmarshalJSON: position of %s has no tick boundaries", pos.Owner)
+		}
+
+		for _, field := range []**big.Int{
+			&pos.Liquidity,
+			&pos.FeeGrowthInside0LastX128,
+			&pos.FeeGrowthInside1LastX128,
+			&pos.TokensOwed0,
+			&pos.TokensOwed1} {
+			if *field == nil {
+				*field = big.NewInt(0)
+			}
+		}
+
+		s.Put(pos)
+	}
+
+	return nil
+}
+
+// UnclaimedFees returns the amounts of token0 and token1 the owner of the position may collect at the current
+// state of the pool, i.e. the tokens already owed plus the fees accrued since the last update of the position.
+// Returns the error of the state reader
+func UnclaimedFees(pos *Position, pool PoolStateReader, ticks *TickStorage) (amount0 *big.Int, amount1 *big.Int, err error) {
+	slot0, err := pool.CurrentState()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	feeGrowthInside0X128, feeGrowthInside1X128 := ticks.GetFeeGrowthInside(pos.TickLower, pos.TickUpper, slot0)
+
+	amount0 = MulDiv(SubUint256(feeGrowthInside0X128, pos.FeeGrowthInside0LastX128), pos.Liquidity, Q128)
+	amount1 = MulDiv(SubUint256(feeGrowthInside1X128, pos.FeeGrowthInside1LastX128), pos.Liquidity, Q128)
+
+	amount0.Add(amount0, pos.TokensOwed0)
+	amount1.Add(amount1, pos.TokensOwed1)
+	return
+}
@@ -0,0 +1,160 @@
+package uniswap_core
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestPositionUpdate(t *testing.T) {
+	pos := NewPosition("alice", big.NewInt(-60), big.NewInt(60))
+	pos.update(big.NewInt(1000), big.NewInt(0), big.NewInt(0), false)
+
+	feeGrowthInside0X128 := big.NewInt(0).Mul(Q128, big.NewInt(2))
+	feeGrowthInside1X128 := big.NewInt(0).Mul(Q128, big.NewInt(7))
+	pos.update(big.NewInt(-400), feeGrowthInside0X128, feeGrowthInside1X128, false)
+
+	if pos.Liquidity.Int64() != 600 {
+		t.Errorf("Position.Liquidity = %d; want %d", pos.Liquidity, 600)
+	}
+
+	if pos.TokensOwed0.Int64() != 2000 || pos.TokensOwed1.Int64() != 7000 {
+		t.Errorf("Position.TokensOwed = %d, %d; want %d, %d", pos.TokensOwed0, pos.TokensOwed1, 2000, 7000)
+	}
+
+	// the fee growth inside may overflow, the difference is still correct
+	pos.FeeGrowthInside0LastX128.Set(MAX_UINT_256)
+	pos.update(big.NewInt(0), big.NewInt(0).Sub(Q128, ONE_UINT_256), feeGrowthInside1X128, false)
+
+	if pos.TokensOwed0.Int64() != 2600 || pos.TokensOwed1.Int64() != 7000 {
+		t.Errorf("Position.TokensOwed = %d, %d; want %d, %d", pos.TokensOwed0, pos.TokensOwed1, 2600, 7000)
+	}
+}
+
+func newTestPositionStore() *PositionStore {
+	s := NewPositionStore()
+	s.Put(NewPosition("bob", big.NewInt(-120), big.NewInt(60)))
+	s.Put(NewPosition("alice", big.NewInt(0), big.NewInt(60)))
+	s.Put(NewPosition("alice", big.NewInt(-120), big.NewInt(60)))
+	s.Put(NewPosition("alice", big.NewInt(-120), big.NewInt(0)))
+	return s
+}
+
+func TestPositionStoreLookup(t *testing.T) {
+	s := newTestPositionStore()
+
+	if s.Len() != 4 {
+		t.Errorf("PositionStore.Len() = %d; want %d", s.Len(), 4)
+	}
+
+	if pos := s.Get("bob", big.NewInt(-120), big.NewInt(60)); pos == nil || pos.Owner != "bob" {
+		t.Errorf("PositionStore.Get(bob, -120, 60) = %v; want bob's position", pos)
+	}
+
+	if pos := s.Get("bob", big.NewInt(0), big.NewInt(60)); pos != nil {
+		t.Errorf("PositionStore.Get(bob, 0, 60) = %v; want nil", pos)
+	}
+
+	refRanges := [][2]int64{{-120, 0}, {-120, 60}, {0, 60}}
+	byOwner := s.ByOwner("alice")
+
+	if len(byOwner) != len(refRanges) {
+		t.Fatalf("len(PositionStore.ByOwner(alice)) = %d; want %d", len(byOwner), len(refRanges))
+	}
+
+	for i, r := range refRanges {
+		if byOwner[i].TickLower.Int64() != r[0] || byOwner[i].TickUpper.Int64() != r[1] {
+			t.Errorf("PositionStore.ByOwner(alice)[%d] = [%d, %d]; want %v",
+				i, byOwner[i].TickLower, byOwner[i].TickUpper, r)
+		}
+	}
+
+	byRange := s.ByRange(big.NewInt(-120), big.NewInt(60))
+
+	if len(byRange) != 2 || byRange[0].Owner != "alice" || byRange[1].Owner != "bob" {
+		t.Errorf("PositionStore.ByRange(-120, 60) = %v; want alice's and bob's positions", byRange)
+	}
+}
+
+func TestPositionStoreJSON(t *testing.T) {
+	s := newTestPositionStore()
+	pos := s.Get("alice", big.NewInt(0), big.NewInt(60))
+	pos.Liquidity.SetInt64(12345)
+	pos.FeeGrowthInside0LastX128.Set(MAX_UINT_256)
+	pos.TokensOwed1.SetInt64(42)
+
+	data, err := json.Marshal(s)
+
+	if err != nil {
+		t.Fatalf("json.Marshal(PositionStore): %s", err)
+	}
+
+	loaded := NewPositionStore()
+
+	if err = json.Unmarshal(data, loaded); err != nil {
+		t.Fatalf("json.Unmarshal(PositionStore): %s", err)
+	}
+
+	if loaded.Len() != s.Len() {
+		t.Errorf("PositionStore.Len() = %d; want %d", loaded.Len(), s.Len())
+	}
+
+	res := loaded.Get("alice", big.NewInt(0), big.NewInt(60))
+
+	if res == nil ||
+		res.Liquidity.Cmp(pos.Liquidity) != 0 ||
+		res.FeeGrowthInside0LastX128.Cmp(pos.FeeGrowthInside0LastX128) != 0 ||
+		res.TokensOwed1.Cmp(pos.TokensOwed1) != 0 {
+		t.Errorf("PositionStore.Get(alice, 0, 60) = %v; want %v", res, pos)
+	}
+
+	again, _ := json.Marshal(loaded)
+
+	if string(again) != string(data) {
+		t.Errorf("json.Marshal(PositionStore) is not deterministic: %s != %s", again, data)
+	}
+}
+
+func TestUnclaimedFees(t *testing.T) {
+	sim := newTestPoolSimulator()
+
+	tickLower := big.NewInt(-60)
+	tickUpper := big.NewInt(60)
+	liquidity, _ := big.NewInt(0).SetString("1000000000000000000", 10)
+
+	if _, _, err := sim.Mint("alice", tickLower, tickUpper, liquidity); err != nil {
+		t.Fatalf("PoolSimulator.Mint(): %s", err)
+	}
+
+	amountSpecified := big.NewInt(1000000000000000)
+	swapOrFatal(t, sim, true, amountSpecified, big.NewInt(0))
+	swapOrFatal(t, sim, false, amountSpecified, big.NewInt(0))
+
+	pos := sim.Positions.Get("alice", tickLower, tickUpper)
+	amount0, amount1, err := UnclaimedFees(pos, sim, sim.Ticks)
+	if err != nil {
+		t.Fatalf("UnclaimedFees(): %s", err)
+	}
+
+	if amount0.Sign() <= 0 || amount1.Sign() <= 0 {
+		t.Errorf("UnclaimedFees() = %d, %d; want positive amounts", amount0, amount1)
+	}
+
+	// poke the position to credit the fees
+	if _, _, err := sim.Burn("alice", tickLower, tickUpper, big.NewInt(0)); err != nil {
+		t.Fatalf("PoolSimulator.Burn(): %s", err)
+	}
+
+	if pos.TokensOwed0.Cmp(amount0) != 0 || pos.TokensOwed1.Cmp(amount1) != 0 {
+		t.Errorf("Position.TokensOwed = %d, %d; want %d, %d", pos.TokensOwed0, pos.TokensOwed1, amount0, amount1)
+	}
+
+	claimed0, claimed1, err := UnclaimedFees(pos, sim, sim.Ticks)
+	if err != nil {
+		t.Fatalf("UnclaimedFees(): %s", err)
+	}
+
+	if claimed0.Cmp(amount0) != 0 || claimed1.Cmp(amount1) != 0 {
+		t.Errorf("UnclaimedFees() = %d, %d; want %d, %d", claimed0, claimed1, amount0, amount1)
+	}
+}
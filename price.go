@@ -8,17 +8,33 @@ import (
 // Calculates liquidity / sqrt(lower) - liquidity / sqrt(upper),
 // i.e. liquidity * (sqrt(upper) - sqrt(lower)) / (sqrt(upper) * sqrt(lower))
 // http://atiselsts.github.io/pdfs/uniswap-v3-liquidity-math.pdf
+// Panics if the price is not positive, see TryGetAmount0DeltaRoundingUp
 func GetAmount0DeltaRoundingUp(
 	sqrtRatioAX96 *big.Int,
 	sqrtRatioBX96 *big.Int,
 	liquidity *big.Int,
 	roundUp bool) (amount0 *big.Int) {
+	amount0, err := TryGetAmount0DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, liquidity, roundUp, false)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// TryGetAmount0DeltaRoundingUp is GetAmount0DeltaRoundingUp which returns an error instead of panicking,
+// if strict the intermediate amount must fit uint256
+func TryGetAmount0DeltaRoundingUp(
+	sqrtRatioAX96 *big.Int,
+	sqrtRatioBX96 *big.Int,
+	liquidity *big.Int,
+	roundUp bool,
+	strict bool) (amount0 *big.Int, err error) {
 	if sqrtRatioAX96.Cmp(sqrtRatioBX96) > 0 {
 		sqrtRatioAX96, sqrtRatioBX96 = sqrtRatioBX96, sqrtRatioAX96
 	}
 
-	if sqrtRatioAX96.Cmp(ZERO_UINT_256) < 0 {
-		panic(fmt.Sprintf("price: sqrtRatioAX96=%d must be non-negative", sqrtRatioAX96))
+	if sqrtRatioAX96.Cmp(ZERO_UINT_256) <= 0 {
+		return nil, fmt.Errorf("%w: price: sqrtRatioAX96 {%d} must be positive", ErrPriceOutOfRange, sqrtRatioAX96)
 	}
 
 	numerator1 := big.NewInt(0)
@@ -28,23 +44,43 @@ func GetAmount0DeltaRoundingUp(
 	numerator2.Sub(sqrtRatioBX96, sqrtRatioAX96)
 
 	if roundUp {
-		amount0 = MulDivRoundingUp(numerator1, numerator2, sqrtRatioBX96)
+		if amount0, err = TryMulDivRoundingUp(numerator1, numerator2, sqrtRatioBX96, strict); err != nil {
+			return
+		}
 		amount0 = DivRoundingUp(amount0, sqrtRatioAX96)
 		return
 	}
 
-	amount0 = MulDiv(numerator1, numerator2, sqrtRatioBX96)
+	if amount0, err = TryMulDiv(numerator1, numerator2, sqrtRatioBX96, strict); err != nil {
+		return
+	}
 	amount0.Div(amount0, sqrtRatioAX96)
 	return
 }
 
 // Calculates liquidity * (sqrt(upper) - sqrt(lower))
 // http://atiselsts.github.io/pdfs/uniswap-v3-liquidity-math.pdf
+// The amount is unbounded, see TryGetAmount1DeltaRoundingUp
 func GetAmount1DeltaRoundingUp(
 	sqrtRatioAX96 *big.Int,
 	sqrtRatioBX96 *big.Int,
 	liquidity *big.Int,
 	roundUp bool) (amount1 *big.Int) {
+	amount1, err := TryGetAmount1DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, liquidity, roundUp, false)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// TryGetAmount1DeltaRoundingUp is GetAmount1DeltaRoundingUp which returns an error instead of panicking,
+// if strict the amount must fit uint256
+func TryGetAmount1DeltaRoundingUp(
+	sqrtRatioAX96 *big.Int,
+	sqrtRatioBX96 *big.Int,
+	liquidity *big.Int,
+	roundUp bool,
+	strict bool) (amount1 *big.Int, err error) {
 	if sqrtRatioAX96.Cmp(sqrtRatioBX96) > 0 {
 		sqrtRatioAX96, sqrtRatioBX96 = sqrtRatioBX96, sqrtRatioAX96
 	}
@@ -56,46 +92,102 @@ func GetAmount1DeltaRoundingUp(
 	numerator2.Sub(sqrtRatioBX96, sqrtRatioAX96)
 
 	if roundUp {
-		amount1 = MulDivRoundingUp(liquidity, numerator2, denumerator)
-		return
+		return TryMulDivRoundingUp(liquidity, numerator2, denumerator, strict)
 	}
 
-	amount1 = MulDiv(liquidity, numerator2, denumerator)
-	return
+	return TryMulDiv(liquidity, numerator2, denumerator, strict)
 }
 
+// Panics on the same conditions as GetAmount0DeltaRoundingUp, see TryGetAmount0Delta
 func GetAmount0Delta(
 	sqrtRatioAX96 *big.Int,
 	sqrtRatioBX96 *big.Int,
 	liquidity *big.Int) (amount0 *big.Int) {
+	amount0, err := TryGetAmount0Delta(sqrtRatioAX96, sqrtRatioBX96, liquidity, false)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// TryGetAmount0Delta returns the signed token0 delta for the signed liquidity delta,
+// if strict the amount must fit int256 (SafeCast)
+func TryGetAmount0Delta(
+	sqrtRatioAX96 *big.Int,
+	sqrtRatioBX96 *big.Int,
+	liquidity *big.Int,
+	strict bool) (amount0 *big.Int, err error) {
 	if liquidity.Cmp(ZERO_UINT_256) < 0 {
 		absLiq := big.NewInt(0)
 		absLiq.Neg(liquidity)
-		amount0 = GetAmount0DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, absLiq, false)
+		if amount0, err = TryGetAmount0DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, absLiq, false, strict); err != nil {
+			return
+		}
+		if err = checkAmountDelta(amount0, strict); err != nil {
+			return nil, err
+		}
 		amount0.Neg(amount0)
 		return
 	}
 
-	amount0 = GetAmount0DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, liquidity, true)
+	if amount0, err = TryGetAmount0DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, liquidity, true, strict); err != nil {
+		return
+	}
+	if err = checkAmountDelta(amount0, strict); err != nil {
+		return nil, err
+	}
 	return
 }
 
+// Panics on the same conditions as GetAmount1DeltaRoundingUp, see TryGetAmount1Delta
 func GetAmount1Delta(
 	sqrtRatioAX96 *big.Int,
 	sqrtRatioBX96 *big.Int,
 	liquidity *big.Int) (amount1 *big.Int) {
+	amount1, err := TryGetAmount1Delta(sqrtRatioAX96, sqrtRatioBX96, liquidity, false)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// TryGetAmount1Delta returns the signed token1 delta for the signed liquidity delta,
+// if strict the amount must fit int256 (SafeCast)
+func TryGetAmount1Delta(
+	sqrtRatioAX96 *big.Int,
+	sqrtRatioBX96 *big.Int,
+	liquidity *big.Int,
+	strict bool) (amount1 *big.Int, err error) {
 	if liquidity.Cmp(ZERO_UINT_256) < 0 {
 		absLiq := big.NewInt(0)
 		absLiq.Neg(liquidity)
-		amount1 = GetAmount1DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, absLiq, false)
+		if amount1, err = TryGetAmount1DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, absLiq, false, strict); err != nil {
+			return
+		}
+		if err = checkAmountDelta(amount1, strict); err != nil {
+			return nil, err
+		}
 		amount1.Neg(amount1)
 		return
 	}
 
-	amount1 = GetAmount1DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, liquidity, true)
+	if amount1, err = TryGetAmount1DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, liquidity, true, strict); err != nil {
+		return
+	}
+	if err = checkAmountDelta(amount1, strict); err != nil {
+		return nil, err
+	}
 	return
 }
 
+func checkAmountDelta(amount *big.Int, strict bool) error {
+	if strict {
+		_, err := ToInt256(amount)
+		return err
+	}
+	return nil
+}
+
 // The most precise formula for this is liquidity * sqrtPX96 / (liquidity +- amount * sqrtPX96),
 // if this is impossible because of overflow, we calculate liquidity / (liquidity / sqrtPX96 +- amount)
 // http://atiselsts.github.io/pdfs/uniswap-v3-liquidity-math.pdf
@@ -104,7 +196,20 @@ func getNextSqrtPriceFromAmount0RoundingUp(
 	sqrtPX96 *big.Int,
 	liquidity *big.Int,
 	amount *big.Int,
-	add bool) (result *big.Int) {
+	add bool) *big.Int {
+	result, err := tryGetNextSqrtPriceFromAmount0RoundingUp(sqrtPX96, liquidity, amount, add, false)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func tryGetNextSqrtPriceFromAmount0RoundingUp(
+	sqrtPX96 *big.Int,
+	liquidity *big.Int,
+	amount *big.Int,
+	add bool,
+	strict bool) (result *big.Int, err error) {
 	if amount.Cmp(ZERO_UINT_256) == 0 {
 		result = big.NewInt(0)
 		result.Set(sqrtPX96)
@@ -117,15 +222,17 @@ func getNextSqrtPriceFromAmount0RoundingUp(
 	product := big.NewInt(0)
 	product.Mul(amount, sqrtPX96)
 
+	// if strict the product and the sum overflowing uint256 take the other branch like in the contract
+	productOverflow := strict && product.Cmp(MAX_UINT_256) > 0
+
 	denominator := big.NewInt(0)
-	denominator.Div(product, amount)
 
 	if add {
-		if denominator.Cmp(sqrtPX96) == 0 {
+		if !productOverflow {
 			denominator.Add(numerator1, product)
 
-			if denominator.Cmp(numerator1) >= 0 {
-				result = MulDivRoundingUp(numerator1, sqrtPX96, denominator)
+			if !strict || denominator.Cmp(MAX_UINT_256) <= 0 {
+				result, err = TryMulDivRoundingUp(numerator1, sqrtPX96, denominator, strict)
 				return
 			}
 		}
@@ -133,16 +240,29 @@ func getNextSqrtPriceFromAmount0RoundingUp(
 		denominator.Div(numerator1, sqrtPX96)
 		denominator.Add(denominator, amount)
 
+		if strict && denominator.Cmp(MAX_UINT_256) > 0 {
+			err = fmt.Errorf("%w: price: numerator1 / sqrtPX96 + amount {%d} exceeds uint256", ErrOverflow, denominator)
+			return
+		}
+
 		result = DivRoundingUp(numerator1, denominator)
 		return
 	}
 
-	if denominator.Cmp(sqrtPX96) != 0 || numerator1.Cmp(product) <= 0 {
-		panic(fmt.Sprintf("price: amount * sqrtPX96 / amount {%d} != sqrtPX96 or numerator1 {%d} <= 0", denominator, numerator1))
+	if productOverflow || numerator1.Cmp(product) <= 0 {
+		err = fmt.Errorf("%w: price: amount * sqrtPX96 {%d} overflows or numerator1 {%d} <= product",
+			ErrInsufficientLiquidity, product, numerator1)
+		return
 	}
 
 	denominator.Sub(numerator1, product)
-	result = MulDivRoundingUp(numerator1, sqrtPX96, denominator)
+	if result, err = TryMulDivRoundingUp(numerator1, sqrtPX96, denominator, strict); err != nil {
+		return
+	}
+
+	if strict {
+		result, err = ToUint160(result)
+	}
 	return
 }
 
@@ -153,7 +273,20 @@ func getNextSqrtPriceFromAmount1RoundingDown(
 	sqrtPX96 *big.Int,
 	liquidity *big.Int,
 	amount *big.Int,
-	add bool) (result *big.Int) {
+	add bool) *big.Int {
+	result, err := tryGetNextSqrtPriceFromAmount1RoundingDown(sqrtPX96, liquidity, amount, add, false)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func tryGetNextSqrtPriceFromAmount1RoundingDown(
+	sqrtPX96 *big.Int,
+	liquidity *big.Int,
+	amount *big.Int,
+	add bool,
+	strict bool) (result *big.Int, err error) {
 
 	var quotient *big.Int
 	result = big.NewInt(0)
@@ -166,10 +299,16 @@ func getNextSqrtPriceFromAmount1RoundingDown(
 		} else {
 			quotient = big.NewInt(1)
 			quotient.Lsh(quotient, 96)
-			quotient = MulDiv(amount, quotient, liquidity)
+			if quotient, err = TryMulDiv(amount, quotient, liquidity, strict); err != nil {
+				return nil, err
+			}
 		}
 
 		result.Add(sqrtPX96, quotient)
+
+		if strict {
+			result, err = ToUint160(result)
+		}
 		return
 	}
 
@@ -180,50 +319,92 @@ func getNextSqrtPriceFromAmount1RoundingDown(
 	} else {
 		quotient = big.NewInt(1)
 		quotient.Lsh(quotient, 96)
-		quotient = MulDivRoundingUp(amount, quotient, liquidity)
+		if quotient, err = TryMulDivRoundingUp(amount, quotient, liquidity, strict); err != nil {
+			return nil, err
+		}
 	}
 
 	if sqrtPX96.Cmp(quotient) <= 0 {
-		panic(fmt.Sprintf("price: sqrtPX96 {%d} <= quotient {%d}", sqrtPX96, quotient))
+		err = fmt.Errorf("%w: price: sqrtPX96 {%d} <= quotient {%d}", ErrInsufficientLiquidity, sqrtPX96, quotient)
+		return
 	}
 
 	result.Sub(sqrtPX96, quotient)
 	return
 }
 
+func checkNextSqrtPriceArgs(sqrtPX96 *big.Int, liquidity *big.Int) error {
+	if sqrtPX96.Cmp(ZERO_UINT_256) <= 0 {
+		return fmt.Errorf("%w: price: sqrtPX96 {%d} must be positive", ErrPriceOutOfRange, sqrtPX96)
+	}
+
+	if liquidity.Cmp(ZERO_UINT_256) <= 0 {
+		return fmt.Errorf("%w: price: liquidity {%d} must be positive", ErrInsufficientLiquidity, liquidity)
+	}
+
+	return nil
+}
+
+// Panics if the arguments are invalid, see TryGetNextSqrtPriceFromInput
 func GetNextSqrtPriceFromInput(
 	sqrtPX96 *big.Int,
 	liquidity *big.Int,
 	amountIn *big.Int,
-	zeroForOne bool) (sqrtQX96 *big.Int) {
+	zeroForOne bool) *big.Int {
+	sqrtQX96, err := TryGetNextSqrtPriceFromInput(sqrtPX96, liquidity, amountIn, zeroForOne, false)
+	if err != nil {
+		panic(err)
+	}
+	return sqrtQX96
+}
 
-	if sqrtPX96.Cmp(ZERO_UINT_256) <= 0 || liquidity.Cmp(ZERO_UINT_256) <= 0 {
-		panic(fmt.Sprintf("price: sqrtPX96 {%d} or liquidity {%d} less than 0", sqrtPX96, liquidity))
+// TryGetNextSqrtPriceFromInput is GetNextSqrtPriceFromInput which returns an error instead of panicking,
+// if strict the price must fit uint160
+func TryGetNextSqrtPriceFromInput(
+	sqrtPX96 *big.Int,
+	liquidity *big.Int,
+	amountIn *big.Int,
+	zeroForOne bool,
+	strict bool) (*big.Int, error) {
+	if err := checkNextSqrtPriceArgs(sqrtPX96, liquidity); err != nil {
+		return nil, err
 	}
 
 	if zeroForOne {
-		sqrtQX96 = getNextSqrtPriceFromAmount0RoundingUp(sqrtPX96, liquidity, amountIn, true)
-		return
+		return tryGetNextSqrtPriceFromAmount0RoundingUp(sqrtPX96, liquidity, amountIn, true, strict)
 	}
 
-	sqrtQX96 = getNextSqrtPriceFromAmount1RoundingDown(sqrtPX96, liquidity, amountIn, true)
-	return
+	return tryGetNextSqrtPriceFromAmount1RoundingDown(sqrtPX96, liquidity, amountIn, true, strict)
 }
 
+// Panics if the arguments are invalid or the liquidity is insufficient, see TryGetNextSqrtPriceFromOutput
 func GetNextSqrtPriceFromOutput(
 	sqrtPX96 *big.Int,
 	liquidity *big.Int,
 	amountOut *big.Int,
-	zeroForOne bool) (sqrtQX96 *big.Int) {
-	if sqrtPX96.Cmp(ZERO_UINT_256) <= 0 || liquidity.Cmp(ZERO_UINT_256) <= 0 {
-		panic(fmt.Sprintf("price: sqrtPX96 {%d} or liquidity {%d} less than 0", sqrtPX96, liquidity))
+	zeroForOne bool) *big.Int {
+	sqrtQX96, err := TryGetNextSqrtPriceFromOutput(sqrtPX96, liquidity, amountOut, zeroForOne, false)
+	if err != nil {
+		panic(err)
+	}
+	return sqrtQX96
+}
+
+// TryGetNextSqrtPriceFromOutput is GetNextSqrtPriceFromOutput which returns an error instead of panicking,
+// if strict the price must fit uint160
+func TryGetNextSqrtPriceFromOutput(
+	sqrtPX96 *big.Int,
+	liquidity *big.Int,
+	amountOut *big.Int,
+	zeroForOne bool,
+	strict bool) (*big.Int, error) {
+	if err := checkNextSqrtPriceArgs(sqrtPX96, liquidity); err != nil {
+		return nil, err
 	}
 
 	if zeroForOne {
-		sqrtQX96 = getNextSqrtPriceFromAmount1RoundingDown(sqrtPX96, liquidity, amountOut, false)
-		return
+		return tryGetNextSqrtPriceFromAmount1RoundingDown(sqrtPX96, liquidity, amountOut, false, strict)
 	}
 
-	sqrtQX96 = getNextSqrtPriceFromAmount0RoundingUp(sqrtPX96, liquidity, amountOut, false)
-	return
+	return tryGetNextSqrtPriceFromAmount0RoundingUp(sqrtPX96, liquidity, amountOut, false, strict)
 }
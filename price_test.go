@@ -1,6 +1,7 @@
 package uniswap_core
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 )
@@ -175,3 +176,94 @@ func TestGetNextSqrtPriceFromAmount1RoundingDown(t *testing.T) {
 		t.Errorf("getNextSqrtPriceFromAmount1RoundingDown() = %d; want %d", result, refRes)
 	}
 }
+
+func TestTryGetNextSqrtPriceFromOutput(t *testing.T) {
+	sqrtPX96 := big.NewInt(1)
+	sqrtPX96.Lsh(sqrtPX96, 96)
+	liquidity := big.NewInt(1000)
+
+	// the output is greater than the reserves of the pool
+	if _, err := TryGetNextSqrtPriceFromOutput(sqrtPX96, liquidity, big.NewInt(1000), true, false); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("TryGetNextSqrtPriceFromOutput() error = %v; want %v", err, ErrInsufficientLiquidity)
+	}
+
+	if _, err := TryGetNextSqrtPriceFromOutput(sqrtPX96, liquidity, big.NewInt(1000), false, false); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("TryGetNextSqrtPriceFromOutput() error = %v; want %v", err, ErrInsufficientLiquidity)
+	}
+
+	if _, err := TryGetNextSqrtPriceFromInput(sqrtPX96, big.NewInt(0), big.NewInt(1), true, false); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("TryGetNextSqrtPriceFromInput() error = %v; want %v", err, ErrInsufficientLiquidity)
+	}
+
+	if _, err := TryGetNextSqrtPriceFromInput(big.NewInt(0), liquidity, big.NewInt(1), true, false); !errors.Is(err, ErrPriceOutOfRange) {
+		t.Errorf("TryGetNextSqrtPriceFromInput() error = %v; want %v", err, ErrPriceOutOfRange)
+	}
+
+	sqrtQX96, err := TryGetNextSqrtPriceFromOutput(sqrtPX96, liquidity, big.NewInt(999), false, false)
+	if err != nil || sqrtQX96.Cmp(sqrtPX96) <= 0 {
+		t.Errorf("TryGetNextSqrtPriceFromOutput() = %d, %v; want greater than %d", sqrtQX96, err, sqrtPX96)
+	}
+}
+
+func FuzzGetAmountDelta(f *testing.F) {
+	f.Add([]byte{}, []byte{0xff}, uint64(0), uint64(10000))
+	f.Add([]byte{}, big.NewInt(0).Sub(MAX_SQRT_RATIO, MIN_SQRT_RATIO).Bytes(), uint64(1<<63), uint64(1))
+	f.Add([]byte{0x01, 0x00, 0x00}, []byte{0x01, 0x00, 0x01}, uint64(0xffffffffffffffff), uint64(0xffffffffffffffff))
+
+	f.Fuzz(func(t *testing.T, a []byte, b []byte, liquidityHi uint64, liquidityLo uint64) {
+		sqrtRatioAX96 := fuzzSqrtPrice(a)
+		sqrtRatioBX96 := fuzzSqrtPrice(b)
+
+		// uint128 liquidity
+		liquidity := big.NewInt(0).SetUint64(liquidityHi)
+		liquidity.Lsh(liquidity, 64)
+		liquidity.Add(liquidity, big.NewInt(0).SetUint64(liquidityLo))
+
+		lower, upper := sqrtRatioAX96, sqrtRatioBX96
+		if lower.Cmp(upper) > 0 {
+			lower, upper = upper, lower
+		}
+
+		one := big.NewInt(1)
+
+		// amount0 * lower * upper ~ (liquidity << 96) * (upper - lower)
+		exact0 := big.NewInt(0).Lsh(liquidity, 96)
+		exact0.Mul(exact0, big.NewInt(0).Sub(upper, lower))
+		product := big.NewInt(0).Mul(lower, upper)
+
+		down0 := GetAmount0DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, liquidity, false)
+		up0 := GetAmount0DeltaRoundingUp(sqrtRatioBX96, sqrtRatioAX96, liquidity, true)
+
+		if big.NewInt(0).Mul(down0, product).Cmp(exact0) > 0 || big.NewInt(0).Mul(up0, product).Cmp(exact0) < 0 {
+			t.Errorf("GetAmount0DeltaRoundingUp(%d, %d, %d) = %d, %d; want rounding down and up", lower, upper, liquidity, down0, up0)
+		}
+
+		if diff := big.NewInt(0).Sub(up0, down0); diff.Sign() < 0 || diff.Cmp(one) > 0 {
+			t.Errorf("GetAmount0DeltaRoundingUp(%d, %d, %d) up - down = %d; want [0, 1]", lower, upper, liquidity, diff)
+		}
+
+		// amount1 << 96 ~ liquidity * (upper - lower)
+		exact1 := big.NewInt(0).Mul(liquidity, big.NewInt(0).Sub(upper, lower))
+
+		down1 := GetAmount1DeltaRoundingUp(sqrtRatioAX96, sqrtRatioBX96, liquidity, false)
+		up1 := GetAmount1DeltaRoundingUp(sqrtRatioBX96, sqrtRatioAX96, liquidity, true)
+
+		if big.NewInt(0).Lsh(down1, 96).Cmp(exact1) > 0 || big.NewInt(0).Lsh(up1, 96).Cmp(exact1) < 0 {
+			t.Errorf("GetAmount1DeltaRoundingUp(%d, %d, %d) = %d, %d; want rounding down and up", lower, upper, liquidity, down1, up1)
+		}
+
+		if diff := big.NewInt(0).Sub(up1, down1); diff.Sign() < 0 || diff.Cmp(one) > 0 {
+			t.Errorf("GetAmount1DeltaRoundingUp(%d, %d, %d) up - down = %d; want [0, 1]", lower, upper, liquidity, diff)
+		}
+
+		// the pool takes rounded up amounts on mint and pays rounded down amounts on burn
+		if amount0 := GetAmount0Delta(sqrtRatioAX96, sqrtRatioBX96, liquidity); amount0.Cmp(up0) != 0 {
+			t.Errorf("GetAmount0Delta(%d, %d, %d) = %d; want %d", lower, upper, liquidity, amount0, up0)
+		}
+
+		negLiquidity := big.NewInt(0).Neg(liquidity)
+		if amount1 := GetAmount1Delta(sqrtRatioAX96, sqrtRatioBX96, negLiquidity); amount1.Cmp(big.NewInt(0).Neg(down1)) != 0 {
+			t.Errorf("GetAmount1Delta(%d, %d, %d) = %d; want -%d", lower, upper, negLiquidity, amount1, down1)
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package uniswap_core
+
+import (
+	"fmt"
+
+	"github.com/holiman/uint256"
+)
+
+// Fixed-width GetAmount0DeltaRoundingUp, stores
+// liquidity * (sqrt(upper) - sqrt(lower)) / (sqrt(upper) * sqrt(lower)) into z
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/SqrtPriceMath.sol
+// Returns ErrOverflow if the amount does not fit into uint256
+func GetAmount0DeltaRoundingUpU256(
+	z *uint256.Int,
+	sqrtRatioAX96 *uint256.Int,
+	sqrtRatioBX96 *uint256.Int,
+	liquidity *uint256.Int,
+	roundUp bool) (*uint256.Int, error) {
+	if sqrtRatioAX96.Gt(sqrtRatioBX96) {
+		sqrtRatioAX96, sqrtRatioBX96 = sqrtRatioBX96, sqrtRatioAX96
+	}
+
+	if sqrtRatioAX96.IsZero() {
+		return nil, fmt.Errorf("%w: price: sqrtRatioAX96 must be positive", ErrPriceOutOfRange)
+	}
+
+	var numerator1, numerator2 uint256.Int
+	numerator1.Lsh(liquidity, 96)
+	numerator2.Sub(sqrtRatioBX96, sqrtRatioAX96)
+
+	if roundUp {
+		if MulDivRoundingUpU256(z, &numerator1, &numerator2, sqrtRatioBX96) {
+			return nil, fmt.Errorf("%w: price: amount0", ErrOverflow)
+		}
+		return DivRoundingUpU256(z, z, sqrtRatioAX96), nil
+	}
+
+	if MulDivU256(z, &numerator1, &numerator2, sqrtRatioBX96) {
+		return nil, fmt.Errorf("%w: price: amount0", ErrOverflow)
+	}
+	return z.Div(z, sqrtRatioAX96), nil
+}
+
+// Fixed-width GetAmount1DeltaRoundingUp, stores liquidity * (sqrt(upper) - sqrt(lower)) into z
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/SqrtPriceMath.sol
+// Returns ErrOverflow if the amount does not fit into uint256
+func GetAmount1DeltaRoundingUpU256(
+	z *uint256.Int,
+	sqrtRatioAX96 *uint256.Int,
+	sqrtRatioBX96 *uint256.Int,
+	liquidity *uint256.Int,
+	roundUp bool) (*uint256.Int, error) {
+	if sqrtRatioAX96.Gt(sqrtRatioBX96) {
+		sqrtRatioAX96, sqrtRatioBX96 = sqrtRatioBX96, sqrtRatioAX96
+	}
+
+	var numerator2 uint256.Int
+	numerator2.Sub(sqrtRatioBX96, sqrtRatioAX96)
+
+	var overflow bool
+	if roundUp {
+		overflow = MulDivRoundingUpU256(z, liquidity, &numerator2, &q96U256)
+	} else {
+		overflow = MulDivU256(z, liquidity, &numerator2, &q96U256)
+	}
+
+	if overflow {
+		return nil, fmt.Errorf("%w: price: amount1", ErrOverflow)
+	}
+	return z, nil
+}
+
+// Fixed-width getNextSqrtPriceFromAmount0RoundingUp, mirrors the overflow checks of the contract
+// add Whether to add, or remove, the amount of token0
+func getNextSqrtPriceFromAmount0RoundingUpU256(
+	z *uint256.Int,
+	sqrtPX96 *uint256.Int,
+	liquidity *uint256.Int,
+	amount *uint256.Int,
+	add bool) (*uint256.Int, error) {
+	if amount.IsZero() {
+		return z.Set(sqrtPX96), nil
+	}
+
+	var numerator1, product, denominator uint256.Int
+	numerator1.Lsh(liquidity, 96)
+	_, productOverflow := product.MulOverflow(amount, sqrtPX96)
+
+	if add {
+		if !productOverflow {
+			if _, overflow := denominator.AddOverflow(&numerator1, &product); !overflow {
+				if MulDivRoundingUpU256(z, &numerator1, sqrtPX96, &denominator) {
+					return nil, fmt.Errorf("%w: price: next sqrt price", ErrOverflow)
+				}
+				return z, nil
+			}
+		}
+
+		denominator.Div(&numerator1, sqrtPX96)
+		if _, overflow := denominator.AddOverflow(&denominator, amount); overflow {
+			return nil, fmt.Errorf("%w: price: numerator1 / sqrtPX96 + amount", ErrOverflow)
+		}
+
+		return DivRoundingUpU256(z, &numerator1, &denominator), nil
+	}
+
+	if productOverflow || !numerator1.Gt(&product) {
+		// the values are copied to keep the arguments on the stack
+		return nil, fmt.Errorf("%w: price: amount * sqrtPX96 {%d} overflows or numerator1 {%d} <= product",
+			ErrInsufficientLiquidity, amount.ToBig(), numerator1.ToBig())
+	}
+
+	denominator.Sub(&numerator1, &product)
+	if MulDivRoundingUpU256(z, &numerator1, sqrtPX96, &denominator) || z.Gt(&maxUint160U256) {
+		return nil, fmt.Errorf("%w: price: next sqrt price", ErrOverflow)
+	}
+	return z, nil
+}
+
+// Fixed-width getNextSqrtPriceFromAmount1RoundingDown, mirrors the overflow checks of the contract
+// add Whether to add, or remove, the amount of token1
+func getNextSqrtPriceFromAmount1RoundingDownU256(
+	z *uint256.Int,
+	sqrtPX96 *uint256.Int,
+	liquidity *uint256.Int,
+	amount *uint256.Int,
+	add bool) (*uint256.Int, error) {
+	var quotient uint256.Int
+
+	if add {
+		if !amount.Gt(&maxUint160U256) {
+			quotient.Lsh(amount, 96)
+			quotient.Div(&quotient, liquidity)
+		} else if MulDivU256(&quotient, amount, &q96U256, liquidity) {
+			return nil, fmt.Errorf("%w: price: quotient", ErrOverflow)
+		}
+
+		if _, overflow := z.AddOverflow(sqrtPX96, &quotient); overflow || z.Gt(&maxUint160U256) {
+			return nil, fmt.Errorf("%w: price: next sqrt price", ErrOverflow)
+		}
+		return z, nil
+	}
+
+	if !amount.Gt(&maxUint160U256) {
+		quotient.Lsh(amount, 96)
+		DivRoundingUpU256(&quotient, &quotient, liquidity)
+	} else if MulDivRoundingUpU256(&quotient, amount, &q96U256, liquidity) {
+		return nil, fmt.Errorf("%w: price: quotient", ErrOverflow)
+	}
+
+	if !sqrtPX96.Gt(&quotient) {
+		return nil, fmt.Errorf("%w: price: sqrtPX96 {%d} <= quotient {%d}", ErrInsufficientLiquidity, sqrtPX96.ToBig(), quotient.ToBig())
+	}
+
+	return z.Sub(sqrtPX96, &quotient), nil
+}
+
+func checkNextSqrtPriceArgsU256(sqrtPX96 *uint256.Int, liquidity *uint256.Int) error {
+	if sqrtPX96.IsZero() {
+		return fmt.Errorf("%w: price: sqrtPX96 must be positive", ErrPriceOutOfRange)
+	}
+
+	if liquidity.IsZero() {
+		return fmt.Errorf("%w: price: liquidity must be positive", ErrInsufficientLiquidity)
+	}
+
+	return nil
+}
+
+// Fixed-width TryGetNextSqrtPriceFromInput, stores the result into z
+func GetNextSqrtPriceFromInputU256(
+	z *uint256.Int,
+	sqrtPX96 *uint256.Int,
+	liquidity *uint256.Int,
+	amountIn *uint256.Int,
+	zeroForOne bool) (*uint256.Int, error) {
+	if err := checkNextSqrtPriceArgsU256(sqrtPX96, liquidity); err != nil {
+		return nil, err
+	}
+
+	if zeroForOne {
+		return getNextSqrtPriceFromAmount0RoundingUpU256(z, sqrtPX96, liquidity, amountIn, true)
+	}
+
+	return getNextSqrtPriceFromAmount1RoundingDownU256(z, sqrtPX96, liquidity, amountIn, true)
+}
+
+// Fixed-width TryGetNextSqrtPriceFromOutput, stores the result into z
+func GetNextSqrtPriceFromOutputU256(
+	z *uint256.Int,
+	sqrtPX96 *uint256.Int,
+	liquidity *uint256.Int,
+	amountOut *uint256.Int,
+	zeroForOne bool) (*uint256.Int, error) {
+	if err := checkNextSqrtPriceArgsU256(sqrtPX96, liquidity); err != nil {
+		return nil, err
+	}
+
+	if zeroForOne {
+		return getNextSqrtPriceFromAmount1RoundingDownU256(z, sqrtPX96, liquidity, amountOut, false)
+	}
+
+	return getNextSqrtPriceFromAmount0RoundingUpU256(z, sqrtPX96, liquidity, amountOut, false)
+}
@@ -0,0 +1,49 @@
+package uniswap_core
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Cast a uint256 to a uint160, returns ErrOverflow on overflow
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/SafeCast.sol
+func ToUint160(y *big.Int) (*big.Int, error) {
+	if y.Sign() < 0 || y.Cmp(MAX_UINT_160) > 0 {
+		return nil, fmt.Errorf("%w: cast: %d does not fit uint160", ErrOverflow, y)
+	}
+	return y, nil
+}
+
+// Cast a int256 to a int128, returns ErrOverflow on overflow or underflow
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/SafeCast.sol
+func ToInt128(y *big.Int) (*big.Int, error) {
+	if y.Cmp(MIN_INT_128) < 0 || y.Cmp(MAX_INT_128) > 0 {
+		return nil, fmt.Errorf("%w: cast: %d does not fit int128", ErrOverflow, y)
+	}
+	return y, nil
+}
+
+// Cast a uint256 to a int256, returns ErrOverflow on overflow
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/SafeCast.sol
+func ToInt256(y *big.Int) (*big.Int, error) {
+	if y.Sign() < 0 || y.Cmp(MAX_INT_256) > 0 {
+		return nil, fmt.Errorf("%w: cast: %d does not fit int256", ErrOverflow, y)
+	}
+	return y, nil
+}
+
+// Returns ErrOverflow unless y fits int256, like the checked int256 addition and subtraction of LowGasSafeMath
+func checkInt256(y *big.Int) error {
+	if y.Cmp(MIN_INT_256) < 0 || y.Cmp(MAX_INT_256) > 0 {
+		return fmt.Errorf("%w: math: %d does not fit int256", ErrOverflow, y)
+	}
+	return nil
+}
+
+// Emulates the unchecked uint128 arithmetic if strict, i.e. sets x to x mod 2**128, x is returned
+func wrapUint128(x *big.Int, strict bool) *big.Int {
+	if strict {
+		x.And(x, MAX_UINT_128)
+	}
+	return x
+}
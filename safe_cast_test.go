@@ -0,0 +1,39 @@
+package uniswap_core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestSafeCast(t *testing.T) {
+	overMaxUint160 := big.NewInt(0).Add(MAX_UINT_160, ONE_UINT_256)
+	overMaxInt128 := big.NewInt(0).Add(MAX_INT_128, ONE_UINT_256)
+	underMinInt128 := big.NewInt(0).Sub(MIN_INT_128, ONE_UINT_256)
+	overMaxInt256 := big.NewInt(0).Add(MAX_INT_256, ONE_UINT_256)
+
+	cases := []struct {
+		name string
+		cast func(*big.Int) (*big.Int, error)
+		ok   []*big.Int
+		fail []*big.Int
+	}{
+		{"ToUint160", ToUint160, []*big.Int{ZERO_UINT_256, MAX_UINT_160}, []*big.Int{big.NewInt(-1), overMaxUint160}},
+		{"ToInt128", ToInt128, []*big.Int{MIN_INT_128, MAX_INT_128}, []*big.Int{underMinInt128, overMaxInt128}},
+		{"ToInt256", ToInt256, []*big.Int{ZERO_UINT_256, MAX_INT_256}, []*big.Int{big.NewInt(-1), overMaxInt256}},
+	}
+
+	for _, c := range cases {
+		for _, y := range c.ok {
+			if res, err := c.cast(y); err != nil || res.Cmp(y) != 0 {
+				t.Errorf("%s(%d) = %d, %v; want %d", c.name, y, res, err, y)
+			}
+		}
+
+		for _, y := range c.fail {
+			if _, err := c.cast(y); !errors.Is(err, ErrOverflow) {
+				t.Errorf("%s(%d) error = %v; want %v", c.name, y, err, ErrOverflow)
+			}
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package uniswap_core
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// PoolSimulator owns the mutable state of a pool and records the results of every operation into it,
+// so consecutive operations see the state the previous ones left behind
+type PoolSimulator struct {
+	Slot0        *Slot0
+	Ticks        *TickStorage
+	ProtocolFees *ProtocolFees
+	Positions    *PositionStore
+	// the observations array of the oracle, it is kept as it is set, e.g. from the pool contract or a snapshot:
+	// the operations of the simulator do not write the oracle
+	Observations []Observation
+	// Strict enforces the bit widths and the revert conditions of the contracts in every operation,
+	// see DoSwapStrict. It is off by default and is inherited by the forks
+	Strict bool
+	// Bitmap is the bitmap of the initialized ticks of Ticks, the swaps step through its words like the pool does
+	// (see TickBitmap) and Mint and Burn flip the ticks in it. It is built by NewPoolSimulator and
+	// PoolSnapshot.Simulator, if it is set to nil the swaps step through Ticks to the next initialized tick
+	Bitmap *TickBitmap
+}
+
+// NewPoolSimulator takes a snapshot of the pool's state, the tick storage is owned and changed by the simulator
+// and the tick bitmap is built from it.
+// Returns the error of the state reader, e.g. ErrFeeTierUnknown
+func NewPoolSimulator(slotReader PoolStateReader, ticks *TickStorage) (*PoolSimulator, error) {
+	slot0, err := slotReader.CurrentState()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PoolSimulator{
+		Slot0:        slot0.Copy(),
+		Ticks:        ticks,
+		ProtocolFees: NewProtocolFees(),
+		Positions:    NewPositionStore(),
+		Bitmap:       NewTickBitmap(ticks)}, nil
+}
+
+// Fork returns a simulator starting from the current state which changes independently of this one,
+// e.g. to evaluate a sequence of operations and throw it away. The ticks and the positions are shared
+// copy-on-write, see TickStorage.Fork. Fork must not be called concurrently with the other methods,
+// the forks may be used concurrently
+func (p *PoolSimulator) Fork() *PoolSimulator {
+	f := &PoolSimulator{
+		Slot0:        p.Slot0.Copy(),
+		Ticks:        p.Ticks.Fork(),
+		ProtocolFees: NewProtocolFees(),
+		Positions:    p.Positions.Fork(),
+		Observations: copyObservations(p.Observations),
+		Strict:       p.Strict}
+
+	if p.Bitmap != nil {
+		f.Bitmap = p.Bitmap.fork(f.Ticks)
+	}
+
+	f.ProtocolFees.Token0.Set(p.ProtocolFees.Token0)
+	f.ProtocolFees.Token1.Set(p.ProtocolFees.Token1)
+
+	return f
+}
+
+func (p *PoolSimulator) CurrentState() (*Slot0, error) {
+	return p.Slot0, nil
+}
+
+// Swap token0 for token1, or token1 for token0, and commit the final price, tick, liquidity,
+// fee growth and protocol fee of the swap into the pool's state.
+// Arguments, results and errors have the same meaning as in DoSwap, the state is not changed on error
+func (p *PoolSimulator) Swap(zeroForOne bool,
+	amountSpecified *big.Int,
+	sqrtPriceLimitX96 *big.Int) (*SwapResult, error) {
+
+	var ticker TickReader = p.Ticks
+	if p.Bitmap != nil {
+		ticker = p.Bitmap
+	}
+
+	result, err := doSwap(zeroForOne, amountSpecified, sqrtPriceLimitX96, ticker, p.Slot0, p.Strict)
+	if err != nil {
+		return nil, err
+	}
+
+	// flip the fee growth outside of the crossed ticks, the global fee growth of the output token is not changed
+	for _, crossed := range result.TicksCrossed {
+		if zeroForOne {
+			p.Ticks.Cross(crossed.Tick, crossed.FeeGrowthGlobalX128, p.Slot0.FeeGrowthGlobal1X128)
+		} else {
+			p.Ticks.Cross(crossed.Tick, p.Slot0.FeeGrowthGlobal0X128, crossed.FeeGrowthGlobalX128)
+		}
+	}
+
+	p.Slot0.SqrtPriceX96.Set(result.SqrtPriceX96)
+	p.Slot0.TickCurrent.Set(result.Tick)
+	p.Slot0.Liquidity.Set(result.Liquidity)
+
+	if zeroForOne {
+		p.Slot0.FeeGrowthGlobal0X128.Add(p.Slot0.FeeGrowthGlobal0X128, result.FeeGrowthGlobalX128Delta)
+		p.Slot0.FeeGrowthGlobal0X128.And(p.Slot0.FeeGrowthGlobal0X128, MAX_UINT_256)
+		wrapUint128(p.ProtocolFees.Token0.Add(p.ProtocolFees.Token0, result.ProtocolFee), p.Strict)
+	} else {
+		p.Slot0.FeeGrowthGlobal1X128.Add(p.Slot0.FeeGrowthGlobal1X128, result.FeeGrowthGlobalX128Delta)
+		p.Slot0.FeeGrowthGlobal1X128.And(p.Slot0.FeeGrowthGlobal1X128, MAX_UINT_256)
+		wrapUint128(p.ProtocolFees.Token1.Add(p.ProtocolFees.Token1, result.ProtocolFee), p.Strict)
+	}
+
+	return result, nil
+}
+
+// Set the denominator of the protocol's % share of the fees
+// feeProtocol0	big.Int	new protocol fee for token0 of the pool
+// feeProtocol1	big.Int	new protocol fee for token1 of the pool
+func (p *PoolSimulator) SetFeeProtocol(feeProtocol0 *big.Int, feeProtocol1 *big.Int) error {
+	for _, feeProtocol := range []*big.Int{feeProtocol0, feeProtocol1} {
+		if feeProtocol.Cmp(ZERO_UINT_256) != 0 && (feeProtocol.Cmp(big.NewInt(4)) < 0 || feeProtocol.Cmp(big.NewInt(10)) > 0) {
+			return fmt.Errorf("%w: PoolSimulator: SetFeeProtocol: fee protocol %d must be 0 or in [4, 10]", ErrFeeProtocolInvalid, feeProtocol)
+		}
+	}
+
+	feeProtocol := big.NewInt(0)
+	feeProtocol.Lsh(feeProtocol1, 4)
+	feeProtocol.Add(feeProtocol, feeProtocol0)
+
+	p.Slot0.FeeProtocol.Set(feeProtocol)
+	return nil
+}
+
+// Common checks for valid tick inputs
+func (p *PoolSimulator) checkTicks(tickLower *big.Int, tickUpper *big.Int) error {
+	if tickLower.Cmp(tickUpper) >= 0 {
+		return fmt.Errorf("%w: PoolSimulator: tickLower %d must be less than tickUpper %d", ErrTickRangeInvalid, tickLower, tickUpper)
+	}
+
+	if tickLower.Cmp(MIN_TICK) < 0 {
+		return fmt.Errorf("%w: PoolSimulator: tickLower %d less than %d", ErrTickRangeInvalid, tickLower, MIN_TICK)
+	}
+
+	if tickUpper.Cmp(MAX_TICK) > 0 {
+		return fmt.Errorf("%w: PoolSimulator: tickUpper %d greater than %d", ErrTickRangeInvalid, tickUpper, MAX_TICK)
+	}
+
+	spacing := big.NewInt(0)
+	if spacing.Mod(tickLower, p.Ticks.TickSpacing).Cmp(ZERO_UINT_256) != 0 ||
+		spacing.Mod(tickUpper, p.Ticks.TickSpacing).Cmp(ZERO_UINT_256) != 0 {
+		return fmt.Errorf("%w: PoolSimulator: ticks [%d, %d] must be multiples of tick spacing %d",
+			ErrTickRangeInvalid, tickLower, tickUpper, p.Ticks.TickSpacing)
+	}
+
+	return nil
+}
+
+// Gets and updates a position with the given liquidity delta
+func (p *PoolSimulator) updatePosition(
+	owner string,
+	tickLower *big.Int,
+	tickUpper *big.Int,
+	liquidityDelta *big.Int) (*Position, error) {
+	pos := p.Positions.getOrCreate(owner, tickLower, tickUpper)
+
+	if liquidityDelta.Cmp(ZERO_UINT_256) == 0 {
+		// disallow pokes for 0 liquidity positions
+		if pos.Liquidity.Cmp(ZERO_UINT_256) <= 0 {
+			return nil, fmt.Errorf("%w: PoolSimulator: position %s [%d, %d] has no liquidity",
+				ErrInsufficientLiquidity, owner, tickLower, tickUpper)
+		}
+	} else if big.NewInt(0).Add(pos.Liquidity, liquidityDelta).Cmp(ZERO_UINT_256) < 0 {
+		return nil, fmt.Errorf("%w: PoolSimulator: position %s [%d, %d] liquidity %d less than %d",
+			ErrInsufficientLiquidity, owner, tickLower, tickUpper, pos.Liquidity, big.NewInt(0).Neg(liquidityDelta))
+	}
+
+	slot0 := p.Slot0
+
+	// if we need to update the ticks, do it, both ticks are checked first to leave the state untouched on error
+	var flippedLower, flippedUpper bool
+	if liquidityDelta.Cmp(ZERO_UINT_256) != 0 {
+		// the tick storage follows the mode of the simulator
+		p.Ticks.Strict = p.Strict
+		if _, _, err := p.Ticks.updatedLiquidity(tickLower, liquidityDelta, false); err != nil {
+			return nil, err
+		}
+		if _, _, err := p.Ticks.updatedLiquidity(tickUpper, liquidityDelta, true); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if flippedLower, err = p.Ticks.TryUpdate(tickLower, slot0.TickCurrent, liquidityDelta,
+			slot0.FeeGrowthGlobal0X128, slot0.FeeGrowthGlobal1X128, false); err != nil {
+			return nil, err
+		}
+		if flippedUpper, err = p.Ticks.TryUpdate(tickUpper, slot0.TickCurrent, liquidityDelta,
+			slot0.FeeGrowthGlobal0X128, slot0.FeeGrowthGlobal1X128, true); err != nil {
+			return nil, err
+		}
+	}
+
+	// the ticks are multiples of the tick spacing, see checkTicks
+	if p.Bitmap != nil {
+		if flippedLower {
+			p.Bitmap.flip(p.Bitmap.compress(tickLower.Int64()))
+		}
+		if flippedUpper {
+			p.Bitmap.flip(p.Bitmap.compress(tickUpper.Int64()))
+		}
+	}
+
+	p.Positions.Put(pos)
+
+	feeGrowthInside0X128, feeGrowthInside1X128 := p.Ticks.getFeeGrowthInside(
+		tickLower, tickUpper, slot0.TickCurrent, slot0.FeeGrowthGlobal0X128, slot0.FeeGrowthGlobal1X128)
+
+	pos.update(liquidityDelta, feeGrowthInside0X128, feeGrowthInside1X128, p.Strict)
+
+	// clear any tick data that is no longer needed
+	if liquidityDelta.Cmp(ZERO_UINT_256) < 0 {
+		if flippedLower {
+			p.Ticks.Clear(tickLower)
+		}
+		if flippedUpper {
+			p.Ticks.Clear(tickUpper)
+		}
+	}
+
+	return pos, nil
+}
+
+// Effect some changes to a position
+// amount0	big.Int	the amount of token0 owed to the pool, negative if the pool should pay the recipient
+// amount1	big.Int	the amount of token1 owed to the pool, negative if the pool should pay the recipient
+func (p *PoolSimulator) modifyPosition(
+	owner string,
+	tickLower *big.Int,
+	tickUpper *big.Int,
+	liquidityDelta *big.Int) (pos *Position, amount0 *big.Int, amount1 *big.Int, err error) {
+	if err = p.checkTicks(tickLower, tickUpper); err != nil {
+		return
+	}
+
+	if p.Strict {
+		if _, err = ToInt128(liquidityDelta); err != nil {
+			return
+		}
+	}
+
+	// the amounts and the liquidity in range are computed before any change, so the state is untouched on error
+	amount0 = big.NewInt(0)
+	amount1 = big.NewInt(0)
+	var liquidity *big.Int
+
+	if liquidityDelta.Cmp(ZERO_UINT_256) != 0 {
+		sqrtRatioLowerX96 := GetSqrtRatioAtTick(tickLower)
+		sqrtRatioUpperX96 := GetSqrtRatioAtTick(tickUpper)
+
+		if p.Slot0.TickCurrent.Cmp(tickLower) < 0 {
+			// current tick is below the passed range; liquidity can only become in range by crossing from left to
+			// right, when we'll need _more_ token0 (it's becoming more valuable) so user must provide it
+			if amount0, err = TryGetAmount0Delta(sqrtRatioLowerX96, sqrtRatioUpperX96, liquidityDelta, p.Strict); err != nil {
+				return
+			}
+		} else if p.Slot0.TickCurrent.Cmp(tickUpper) < 0 {
+			// current tick is inside the passed range
+			if amount0, err = TryGetAmount0Delta(p.Slot0.SqrtPriceX96, sqrtRatioUpperX96, liquidityDelta, p.Strict); err != nil {
+				return
+			}
+			if amount1, err = TryGetAmount1Delta(sqrtRatioLowerX96, p.Slot0.SqrtPriceX96, liquidityDelta, p.Strict); err != nil {
+				return
+			}
+			if liquidity, err = TryAddLiquidityDelta(p.Slot0.Liquidity, liquidityDelta, p.Strict); err != nil {
+				return
+			}
+		} else {
+			// current tick is above the passed range; liquidity can only become in range by crossing from right to
+			// left, when we'll need _more_ token1 (it's becoming more valuable) so user must provide it
+			if amount1, err = TryGetAmount1Delta(sqrtRatioLowerX96, sqrtRatioUpperX96, liquidityDelta, p.Strict); err != nil {
+				return
+			}
+		}
+	}
+
+	if pos, err = p.updatePosition(owner, tickLower, tickUpper, liquidityDelta); err != nil {
+		return
+	}
+
+	if liquidity != nil {
+		p.Slot0.Liquidity.Set(liquidity)
+	}
+
+	return
+}
+
+// Adds liquidity for the given owner/tickLower/tickUpper position
+// owner	string	The address for which the liquidity will be created
+// tickLower	big.Int	The lower tick of the position in which to add liquidity
+// tickUpper	big.Int	The upper tick of the position in which to add liquidity
+// liquidity	big.Int	The amount of liquidity to mint
+// amount0	big.Int	The amount of token0 that was paid to mint the given amount of liquidity
+// amount1	big.Int	The amount of token1 that was paid to mint the given amount of liquidity
+func (p *PoolSimulator) Mint(
+	owner string,
+	tickLower *big.Int,
+	tickUpper *big.Int,
+	liquidity *big.Int) (amount0 *big.Int, amount1 *big.Int, err error) {
+	if liquidity.Cmp(ZERO_UINT_256) <= 0 {
+		return nil, nil, fmt.Errorf("%w: PoolSimulator: Mint: liquidity %d must be positive", ErrLiquidityInvalid, liquidity)
+	}
+
+	_, amount0, amount1, err = p.modifyPosition(owner, tickLower, tickUpper, liquidity)
+	return
+}
+
+// Burn liquidity from the sender and account tokens owed for the liquidity to the position
+// Can be used to trigger a recalculation of fees owed to a position by calling with an amount of 0
+// owner	string	The owner of the position
+// tickLower	big.Int	The lower tick of the position for which to burn liquidity
+// tickUpper	big.Int	The upper tick of the position for which to burn liquidity
+// liquidity	big.Int	How much liquidity to burn
+// amount0	big.Int	The amount of token0 sent to the recipient
+// amount1	big.Int	The amount of token1 sent to the recipient
+func (p *PoolSimulator) Burn(
+	owner string,
+	tickLower *big.Int,
+	tickUpper *big.Int,
+	liquidity *big.Int) (amount0 *big.Int, amount1 *big.Int, err error) {
+	if liquidity.Cmp(ZERO_UINT_256) < 0 {
+		return nil, nil, fmt.Errorf("%w: PoolSimulator: Burn: liquidity %d must be non-negative", ErrLiquidityInvalid, liquidity)
+	}
+
+	var pos *Position
+	liquidityDelta := big.NewInt(0).Neg(liquidity)
+
+	if pos, amount0, amount1, err = p.modifyPosition(owner, tickLower, tickUpper, liquidityDelta); err != nil {
+		return
+	}
+
+	amount0.Neg(amount0)
+	amount1.Neg(amount1)
+
+	wrapUint128(pos.TokensOwed0.Add(pos.TokensOwed0, amount0), p.Strict)
+	wrapUint128(pos.TokensOwed1.Add(pos.TokensOwed1, amount1), p.Strict)
+
+	return
+}
+
+func minBigInt(a *big.Int, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return big.NewInt(0).Set(a)
+	}
+	return big.NewInt(0).Set(b)
+}
+
+// The requested amounts are uint128 in the pool, the negative ones are rejected
+func checkRequested(method string, amount0Requested *big.Int, amount1Requested *big.Int) error {
+	if amount0Requested.Sign() < 0 || amount1Requested.Sign() < 0 {
+		return fmt.Errorf("%w: PoolSimulator: %s: requested amounts %d, %d must be non-negative", ErrAmountRequestedInvalid,
+			method, amount0Requested, amount1Requested)
+	}
+	return nil
+}
+
+// Collects tokens owed to a position
+// owner	string	The owner of the position
+// tickLower	big.Int	The lower tick of the position for which to collect fees
+// tickUpper	big.Int	The upper tick of the position for which to collect fees
+// amount0Requested	big.Int	How much token0 should be withdrawn from the fees owed
+// amount1Requested	big.Int	How much token1 should be withdrawn from the fees owed
+// amount0	big.Int	The amount of fees collected in token0
+// amount1	big.Int	The amount of fees collected in token1
+func (p *PoolSimulator) Collect(
+	owner string,
+	tickLower *big.Int,
+	tickUpper *big.Int,
+	amount0Requested *big.Int,
+	amount1Requested *big.Int) (amount0 *big.Int, amount1 *big.Int, err error) {
+	if err = checkRequested("Collect", amount0Requested, amount1Requested); err != nil {
+		return
+	}
+
+	pos := p.Positions.mutable(owner, tickLower, tickUpper)
+	if pos == nil {
+		return big.NewInt(0), big.NewInt(0), nil
+	}
+
+	amount0 = minBigInt(amount0Requested, pos.TokensOwed0)
+	amount1 = minBigInt(amount1Requested, pos.TokensOwed1)
+
+	pos.TokensOwed0.Sub(pos.TokensOwed0, amount0)
+	pos.TokensOwed1.Sub(pos.TokensOwed1, amount1)
+
+	return
+}
+
+// Collect the protocol fee accrued to the pool
+// amount0Requested	big.Int	The maximum amount of token0 to send, can be 0 to collect fees in only token1
+// amount1Requested	big.Int	The maximum amount of token1 to send, can be 0 to collect fees in only token0
+// amount0	big.Int	The protocol fee collected in token0
+// amount1	big.Int	The protocol fee collected in token1
+func (p *PoolSimulator) CollectProtocol(
+	amount0Requested *big.Int,
+	amount1Requested *big.Int) (amount0 *big.Int, amount1 *big.Int, err error) {
+	if err = checkRequested("CollectProtocol", amount0Requested, amount1Requested); err != nil {
+		return
+	}
+
+	amount0 = minBigInt(amount0Requested, p.ProtocolFees.Token0)
+	amount1 = minBigInt(amount1Requested, p.ProtocolFees.Token1)
+
+	if amount0.Cmp(ZERO_UINT_256) > 0 {
+		// ensure that the slot is not cleared, for gas savings
+		if amount0.Cmp(p.ProtocolFees.Token0) == 0 {
+			amount0.Sub(amount0, ONE_UINT_256)
+		}
+		p.ProtocolFees.Token0.Sub(p.ProtocolFees.Token0, amount0)
+	}
+
+	if amount1.Cmp(ZERO_UINT_256) > 0 {
+		// ensure that the slot is not cleared, for gas savings
+		if amount1.Cmp(p.ProtocolFees.Token1) == 0 {
+			amount1.Sub(amount1, ONE_UINT_256)
+		}
+		p.ProtocolFees.Token1.Sub(p.ProtocolFees.Token1, amount1)
+	}
+
+	return
+}
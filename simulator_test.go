@@ -0,0 +1,716 @@
+package uniswap_core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// newTestPool returns a 0.3% pool at tick 0 with two positions:
+// [-600, 600] with liquidity 1e18 and [-1200, 1200] with liquidity 2e18
+func newTestPool() (*Pool, []Tick) {
+	liquidity0, _ := big.NewInt(0).SetString("1000000000000000000", 10)
+	liquidity1, _ := big.NewInt(0).SetString("2000000000000000000", 10)
+
+	pool := &Pool{
+		FeeTier:              BigInt{Val: big.NewInt(3000)},
+		Tick:                 BigInt{Val: big.NewInt(0)},
+		SqrtPrice:            BigInt{Val: GetSqrtRatioAtTick(big.NewInt(0))},
+		Liquidity:            BigInt{Val: big.NewInt(0).Add(liquidity0, liquidity1)},
+		FeeGrowthGlobal0X128: BigInt{Val: big.NewInt(0)},
+		FeeGrowthGlobal1X128: BigInt{Val: big.NewInt(0)},
+	}
+
+	ranges := []struct {
+		tick      int64
+		liquidity *big.Int
+	}{
+		{-1200, liquidity1},
+		{-600, liquidity0},
+		{600, big.NewInt(0).Neg(liquidity0)},
+		{1200, big.NewInt(0).Neg(liquidity1)},
+	}
+
+	ticks := make([]Tick, len(ranges))
+
+	for i, r := range ranges {
+		ticks[i] = Tick{
+			TickIdx:               BigInt{Val: big.NewInt(r.tick)},
+			LiquidityGross:        BigInt{Val: big.NewInt(0).Abs(r.liquidity)},
+			LiquidityNet:          BigInt{Val: big.NewInt(0).Set(r.liquidity)},
+			FeeGrowthOutside0X128: BigInt{Val: big.NewInt(0)},
+			FeeGrowthOutside1X128: BigInt{Val: big.NewInt(0)},
+		}
+	}
+
+	return pool, ticks
+}
+
+func newTestPoolSimulator() *PoolSimulator {
+	pool, ticks := newTestPool()
+	sim, err := NewPoolSimulator(pool, NewTickStorage(ticks, pool.MustFeeTierToTickSpacing()))
+	if err != nil {
+		panic(err)
+	}
+	return sim
+}
+
+func swapOrFatal(t *testing.T, sim *PoolSimulator,
+	zeroForOne bool, amountSpecified *big.Int, sqrtPriceLimitX96 *big.Int) *SwapResult {
+	t.Helper()
+
+	res, err := sim.Swap(zeroForOne, amountSpecified, sqrtPriceLimitX96)
+	if err != nil {
+		t.Fatalf("PoolSimulator.Swap(): %s", err)
+	}
+	return res
+}
+
+func TestPoolSimulatorSwap(t *testing.T) {
+	sim := newTestPoolSimulator()
+	amountSpecified := big.NewInt(1000000000000000)
+
+	for i := 0; i < 2; i++ {
+		sqrtPriceStartX96 := big.NewInt(0).Set(sim.Slot0.SqrtPriceX96)
+		ref, err := DoSwap(true, amountSpecified, big.NewInt(0), sim.Ticks, sim)
+
+		if err != nil {
+			t.Fatalf("DoSwap(): %s", err)
+		}
+
+		res := swapOrFatal(t, sim, true, amountSpecified, big.NewInt(0))
+
+		if res.Amount0.Cmp(ref.Amount0) != 0 || res.Amount1.Cmp(ref.Amount1) != 0 || res.FeeTotal.Cmp(ref.FeeTotal) != 0 {
+			t.Errorf("PoolSimulator.Swap() #%d = %d, %d, %d; want %d, %d, %d",
+				i, res.Amount0, res.Amount1, res.FeeTotal, ref.Amount0, ref.Amount1, ref.FeeTotal)
+		}
+
+		if res.SqrtPriceX96.Cmp(sim.Slot0.SqrtPriceX96) != 0 || res.Tick.Cmp(sim.Slot0.TickCurrent) != 0 {
+			t.Errorf("SwapResult.SqrtPriceX96, Tick = %d, %d; want %d, %d",
+				res.SqrtPriceX96, res.Tick, sim.Slot0.SqrtPriceX96, sim.Slot0.TickCurrent)
+		}
+
+		if len(res.TicksCrossed) != 0 || res.PriceLimitReached {
+			t.Errorf("SwapResult.TicksCrossed, PriceLimitReached = %v, %t; want [], false",
+				res.TicksCrossed, res.PriceLimitReached)
+		}
+
+		if sim.Slot0.SqrtPriceX96.Cmp(sqrtPriceStartX96) >= 0 {
+			t.Errorf("PoolSimulator.Slot0.SqrtPriceX96 = %d; want less than %d", sim.Slot0.SqrtPriceX96, sqrtPriceStartX96)
+		}
+
+		tick := GetTickAtSqrtRatio(sim.Slot0.SqrtPriceX96)
+		if sim.Slot0.TickCurrent.Cmp(tick) != 0 {
+			t.Errorf("PoolSimulator.Slot0.TickCurrent = %d; want %d", sim.Slot0.TickCurrent, tick)
+		}
+	}
+}
+
+func TestPoolSimulatorSwapCrossTick(t *testing.T) {
+	sim := newTestPoolSimulator()
+	_, ticks := newTestPool()
+
+	amountSpecified, _ := big.NewInt(0).SetString("1000000000000000000000", 10)
+	sqrtPriceLimitX96 := GetSqrtRatioAtTick(big.NewInt(-900))
+
+	res := swapOrFatal(t, sim, true, amountSpecified, sqrtPriceLimitX96)
+
+	if !res.PriceLimitReached {
+		t.Errorf("SwapResult.PriceLimitReached = %t; want %t", res.PriceLimitReached, true)
+	}
+
+	if len(res.TicksCrossed) != 1 ||
+		res.TicksCrossed[0].Tick.Cmp(ticks[1].TickIdx.Val) != 0 ||
+		res.TicksCrossed[0].LiquidityNet.Cmp(ticks[1].LiquidityNet.Val) != 0 {
+		t.Errorf("SwapResult.TicksCrossed = %v; want tick %d", res.TicksCrossed, ticks[1].TickIdx.Val)
+	}
+
+	if sim.Slot0.SqrtPriceX96.Cmp(sqrtPriceLimitX96) != 0 {
+		t.Errorf("PoolSimulator.Slot0.SqrtPriceX96 = %d; want %d", sim.Slot0.SqrtPriceX96, sqrtPriceLimitX96)
+	}
+
+	if sim.Slot0.TickCurrent.Cmp(big.NewInt(-900)) != 0 {
+		t.Errorf("PoolSimulator.Slot0.TickCurrent = %d; want %d", sim.Slot0.TickCurrent, -900)
+	}
+
+	refLiquidity := ticks[0].LiquidityNet.Val
+	if sim.Slot0.Liquidity.Cmp(refLiquidity) != 0 {
+		t.Errorf("PoolSimulator.Slot0.Liquidity = %d; want %d", sim.Slot0.Liquidity, refLiquidity)
+	}
+
+	res = swapOrFatal(t, sim, false, amountSpecified, GetSqrtRatioAtTick(big.NewInt(0)))
+
+	if res.Amount0.Sign() >= 0 || res.Amount1.Sign() <= 0 {
+		t.Errorf("PoolSimulator.Swap() = %d, %d; want negative amount0 and positive amount1", res.Amount0, res.Amount1)
+	}
+
+	if sim.Slot0.TickCurrent.Cmp(big.NewInt(0)) != 0 {
+		t.Errorf("PoolSimulator.Slot0.TickCurrent = %d; want %d", sim.Slot0.TickCurrent, 0)
+	}
+
+	refLiquidity = big.NewInt(0).Add(ticks[0].LiquidityNet.Val, ticks[1].LiquidityNet.Val)
+	if sim.Slot0.Liquidity.Cmp(refLiquidity) != 0 {
+		t.Errorf("PoolSimulator.Slot0.Liquidity = %d; want %d", sim.Slot0.Liquidity, refLiquidity)
+	}
+}
+
+func TestPoolSimulatorMint(t *testing.T) {
+	sim := newTestPoolSimulator()
+	sim.Slot0.FeeGrowthGlobal0X128.SetInt64(100)
+	sim.Slot0.FeeGrowthGlobal1X128.SetInt64(200)
+
+	liquidity := big.NewInt(1000000000)
+	liquidityStart := big.NewInt(0).Set(sim.Slot0.Liquidity)
+	tickLower := big.NewInt(-60)
+	tickUpper := big.NewInt(60)
+
+	amount0, amount1, err := sim.Mint("alice", tickLower, tickUpper, liquidity)
+
+	if err != nil {
+		t.Fatalf("PoolSimulator.Mint(): %s", err)
+	}
+
+	refAmount0 := GetAmount0Delta(sim.Slot0.SqrtPriceX96, GetSqrtRatioAtTick(tickUpper), liquidity)
+	refAmount1 := GetAmount1Delta(GetSqrtRatioAtTick(tickLower), sim.Slot0.SqrtPriceX96, liquidity)
+
+	if amount0.Cmp(refAmount0) != 0 || amount1.Cmp(refAmount1) != 0 {
+		t.Errorf("PoolSimulator.Mint() = %d, %d; want %d, %d", amount0, amount1, refAmount0, refAmount1)
+	}
+
+	refLiquidity := big.NewInt(0).Add(liquidityStart, liquidity)
+	if sim.Slot0.Liquidity.Cmp(refLiquidity) != 0 {
+		t.Errorf("PoolSimulator.Slot0.Liquidity = %d; want %d", sim.Slot0.Liquidity, refLiquidity)
+	}
+
+	if !sim.Ticks.IsInitialized(tickLower) || !sim.Ticks.IsInitialized(tickUpper) {
+		t.Errorf("ticks %d, %d must be initialized", tickLower, tickUpper)
+	}
+
+	if liqNet := sim.Ticks.GetLiquidityNet(tickUpper); liqNet.Cmp(big.NewInt(0).Neg(liquidity)) != 0 {
+		t.Errorf("GetLiquidityNet(%d) = %d; want %d", tickUpper, liqNet, big.NewInt(0).Neg(liquidity))
+	}
+
+	lower := sim.Ticks.Ticks[tickLower.Int64()]
+	if lower.FeeGrowthOutside0X128.Val.Cmp(sim.Slot0.FeeGrowthGlobal0X128) != 0 ||
+		lower.FeeGrowthOutside1X128.Val.Cmp(sim.Slot0.FeeGrowthGlobal1X128) != 0 {
+		t.Errorf("tick %d fee growth outside = %d, %d; want %d, %d", tickLower,
+			lower.FeeGrowthOutside0X128.Val, lower.FeeGrowthOutside1X128.Val,
+			sim.Slot0.FeeGrowthGlobal0X128, sim.Slot0.FeeGrowthGlobal1X128)
+	}
+
+	upper := sim.Ticks.Ticks[tickUpper.Int64()]
+	if upper.FeeGrowthOutside0X128.Val.Sign() != 0 || upper.FeeGrowthOutside1X128.Val.Sign() != 0 {
+		t.Errorf("tick %d fee growth outside = %d, %d; want 0, 0", tickUpper,
+			upper.FeeGrowthOutside0X128.Val, upper.FeeGrowthOutside1X128.Val)
+	}
+
+	// below the current price the position consists of token1 only
+	tickLower = big.NewInt(-1200)
+	tickUpper = big.NewInt(-600)
+	liqGrossBefore := big.NewInt(0).Set(sim.Ticks.Ticks[tickUpper.Int64()].LiquidityGross.Val)
+
+	amount0, amount1, err = sim.Mint("bob", tickLower, tickUpper, liquidity)
+
+	if err != nil {
+		t.Fatalf("PoolSimulator.Mint(): %s", err)
+	}
+
+	refAmount1 = GetAmount1Delta(GetSqrtRatioAtTick(tickLower), GetSqrtRatioAtTick(tickUpper), liquidity)
+
+	if amount0.Sign() != 0 || amount1.Cmp(refAmount1) != 0 {
+		t.Errorf("PoolSimulator.Mint() = %d, %d; want 0, %d", amount0, amount1, refAmount1)
+	}
+
+	if sim.Slot0.Liquidity.Cmp(refLiquidity) != 0 {
+		t.Errorf("PoolSimulator.Slot0.Liquidity = %d; want %d", sim.Slot0.Liquidity, refLiquidity)
+	}
+
+	liqGrossBefore.Add(liqGrossBefore, liquidity)
+	if liqGross := sim.Ticks.Ticks[tickUpper.Int64()].LiquidityGross.Val; liqGross.Cmp(liqGrossBefore) != 0 {
+		t.Errorf("tick %d LiquidityGross = %d; want %d", tickUpper, liqGross, liqGrossBefore)
+	}
+}
+
+func TestPoolSimulatorMintInvalid(t *testing.T) {
+	sim := newTestPoolSimulator()
+	liquidity := big.NewInt(1000)
+
+	cases := [][2]int64{{60, -60}, {60, 60}, {-887280, 60}, {-60, 887280}, {-61, 60}}
+
+	for _, c := range cases {
+		_, _, err := sim.Mint("alice", big.NewInt(c[0]), big.NewInt(c[1]), liquidity)
+
+		if err == nil {
+			t.Errorf("PoolSimulator.Mint(%d, %d) must fail", c[0], c[1])
+		}
+	}
+
+	if _, _, err := sim.Mint("alice", big.NewInt(-60), big.NewInt(60), big.NewInt(0)); !errors.Is(err, ErrLiquidityInvalid) {
+		t.Errorf("PoolSimulator.Mint() with zero liquidity error = %v; want %v", err, ErrLiquidityInvalid)
+	}
+
+	if _, _, err := sim.Burn("alice", big.NewInt(-60), big.NewInt(60), big.NewInt(-1)); !errors.Is(err, ErrLiquidityInvalid) {
+		t.Errorf("PoolSimulator.Burn() with negative liquidity error = %v; want %v", err, ErrLiquidityInvalid)
+	}
+}
+
+func TestPoolSimulatorMintStrict(t *testing.T) {
+	sim := newTestPoolSimulator()
+	sim.Strict = true
+	tickLower, tickUpper := big.NewInt(-60), big.NewInt(60)
+
+	// lift the 'LO' limit to reach the uint128 bounds
+	sim.Ticks.MaxLiquidityPerTick = MAX_UINT_128
+
+	// int256(amount).toInt128()
+	overMaxInt128 := big.NewInt(0).Add(MAX_INT_128, ONE_UINT_256)
+	if _, _, err := sim.Mint("alice", tickLower, tickUpper, overMaxInt128); !errors.Is(err, ErrOverflow) {
+		t.Errorf("PoolSimulator.Mint(%d) error = %v; want %v", overMaxInt128, err, ErrOverflow)
+	}
+
+	if _, _, err := sim.Mint("alice", tickLower, tickUpper, MAX_INT_128); err != nil {
+		t.Fatalf("PoolSimulator.Mint(%d): %s", MAX_INT_128, err)
+	}
+
+	liquidity := big.NewInt(0).Set(sim.Slot0.Liquidity)
+
+	// the second mint overflows the uint128 gross liquidity of the ticks ('LA'), nothing is changed
+	if _, _, err := sim.Mint("bob", tickLower, tickUpper, MAX_INT_128); !errors.Is(err, ErrOverflow) {
+		t.Errorf("PoolSimulator.Mint(%d) error = %v; want %v", MAX_INT_128, err, ErrOverflow)
+	}
+
+	if sim.Slot0.Liquidity.Cmp(liquidity) != 0 {
+		t.Errorf("Slot0.Liquidity = %d; want %d", sim.Slot0.Liquidity, liquidity)
+	}
+
+	if liqGross := sim.Ticks.Ticks[-60].LiquidityGross.Val; liqGross.Cmp(MAX_INT_128) != 0 {
+		t.Errorf("tick -60 LiquidityGross = %d; want %d", liqGross, MAX_INT_128)
+	}
+
+	if sim.Positions.Get("bob", tickLower, tickUpper) != nil {
+		t.Errorf("PoolSimulator.Mint() stored the position of the failed mint")
+	}
+}
+
+// the mode belongs to the simulator, the simulators in the other mode are not affected
+func TestPoolSimulatorStrictPerInstance(t *testing.T) {
+	strict := newTestPoolSimulator()
+	strict.Strict = true
+	unbounded := newTestPoolSimulator()
+
+	tickLower, tickUpper := big.NewInt(-60), big.NewInt(60)
+	overMaxInt128 := big.NewInt(0).Add(MAX_INT_128, ONE_UINT_256)
+
+	for _, sim := range []*PoolSimulator{strict, unbounded} {
+		sim.Ticks.MaxLiquidityPerTick = MAX_UINT_256
+	}
+
+	if _, _, err := strict.Fork().Mint("alice", tickLower, tickUpper, overMaxInt128); !errors.Is(err, ErrOverflow) {
+		t.Errorf("PoolSimulator.Fork().Mint(%d) error = %v; want %v", overMaxInt128, err, ErrOverflow)
+	}
+
+	if _, _, err := unbounded.Mint("alice", tickLower, tickUpper, overMaxInt128); err != nil {
+		t.Errorf("PoolSimulator.Mint(%d): %s", overMaxInt128, err)
+	}
+}
+
+func TestPoolSimulatorBurn(t *testing.T) {
+	sim := newTestPoolSimulator()
+
+	liquidity := big.NewInt(1000000000)
+	liquidityStart := big.NewInt(0).Set(sim.Slot0.Liquidity)
+	tickLower := big.NewInt(-60)
+	tickUpper := big.NewInt(60)
+
+	if _, _, err := sim.Mint("alice", tickLower, tickUpper, liquidity); err != nil {
+		t.Fatalf("PoolSimulator.Mint(): %s", err)
+	}
+
+	// accrue 3 units of token0 and 5 units of token1 per unit of liquidity
+	sim.Slot0.FeeGrowthGlobal0X128.Mul(Q128, big.NewInt(3))
+	sim.Slot0.FeeGrowthGlobal1X128.Mul(Q128, big.NewInt(5))
+
+	half := big.NewInt(0).Div(liquidity, big.NewInt(2))
+
+	amount0, amount1, err := sim.Burn("alice", tickLower, tickUpper, half)
+
+	if err != nil {
+		t.Fatalf("PoolSimulator.Burn(): %s", err)
+	}
+
+	refAmount0 := GetAmount0DeltaRoundingUp(sim.Slot0.SqrtPriceX96, GetSqrtRatioAtTick(tickUpper), half, false)
+	refAmount1 := GetAmount1DeltaRoundingUp(GetSqrtRatioAtTick(tickLower), sim.Slot0.SqrtPriceX96, half, false)
+
+	if amount0.Cmp(refAmount0) != 0 || amount1.Cmp(refAmount1) != 0 {
+		t.Errorf("PoolSimulator.Burn() = %d, %d; want %d, %d", amount0, amount1, refAmount0, refAmount1)
+	}
+
+	pos := sim.Positions.Get("alice", tickLower, tickUpper)
+
+	refOwed0 := big.NewInt(0).Mul(liquidity, big.NewInt(3))
+	refOwed0.Add(refOwed0, refAmount0)
+	refOwed1 := big.NewInt(0).Mul(liquidity, big.NewInt(5))
+	refOwed1.Add(refOwed1, refAmount1)
+
+	if pos.TokensOwed0.Cmp(refOwed0) != 0 || pos.TokensOwed1.Cmp(refOwed1) != 0 {
+		t.Errorf("Position.TokensOwed = %d, %d; want %d, %d", pos.TokensOwed0, pos.TokensOwed1, refOwed0, refOwed1)
+	}
+
+	if _, _, err = sim.Burn("alice", tickLower, tickUpper, liquidity); err == nil {
+		t.Errorf("PoolSimulator.Burn() of more than the position's liquidity must fail")
+	}
+
+	if _, _, err = sim.Burn("alice", tickLower, tickUpper, half); err != nil {
+		t.Fatalf("PoolSimulator.Burn(): %s", err)
+	}
+
+	if sim.Slot0.Liquidity.Cmp(liquidityStart) != 0 {
+		t.Errorf("PoolSimulator.Slot0.Liquidity = %d; want %d", sim.Slot0.Liquidity, liquidityStart)
+	}
+
+	if _, ok := sim.Ticks.Ticks[tickLower.Int64()]; ok {
+		t.Errorf("tick %d must be cleared", tickLower)
+	}
+
+	if _, ok := sim.Ticks.Ticks[tickUpper.Int64()]; ok {
+		t.Errorf("tick %d must be cleared", tickUpper)
+	}
+
+	if _, _, err = sim.Burn("alice", tickLower, tickUpper, big.NewInt(0)); err == nil {
+		t.Errorf("PoolSimulator.Burn() of an empty position must fail")
+	}
+}
+
+func TestPoolSimulatorCollect(t *testing.T) {
+	sim := newTestPoolSimulator()
+
+	liquidity := big.NewInt(1000000000)
+	tickLower := big.NewInt(-60)
+	tickUpper := big.NewInt(60)
+
+	if _, _, err := sim.Mint("alice", tickLower, tickUpper, liquidity); err != nil {
+		t.Fatalf("PoolSimulator.Mint(): %s", err)
+	}
+
+	burned0, burned1, err := sim.Burn("alice", tickLower, tickUpper, liquidity)
+
+	if err != nil {
+		t.Fatalf("PoolSimulator.Burn(): %s", err)
+	}
+
+	requested0 := big.NewInt(100)
+	amount0, amount1, err := sim.Collect("alice", tickLower, tickUpper, requested0, big.NewInt(0))
+	if err != nil {
+		t.Fatalf("PoolSimulator.Collect(): %s", err)
+	}
+
+	if amount0.Cmp(requested0) != 0 || amount1.Sign() != 0 {
+		t.Errorf("PoolSimulator.Collect() = %d, %d; want %d, 0", amount0, amount1, requested0)
+	}
+
+	maxRequested := GetMaxValue(128)
+	amount0, amount1, err = sim.Collect("alice", tickLower, tickUpper, maxRequested, maxRequested)
+	if err != nil {
+		t.Fatalf("PoolSimulator.Collect(): %s", err)
+	}
+
+	refAmount0 := big.NewInt(0).Sub(burned0, requested0)
+	if amount0.Cmp(refAmount0) != 0 || amount1.Cmp(burned1) != 0 {
+		t.Errorf("PoolSimulator.Collect() = %d, %d; want %d, %d", amount0, amount1, refAmount0, burned1)
+	}
+
+	amount0, amount1, err = sim.Collect("alice", tickLower, tickUpper, maxRequested, maxRequested)
+	if err != nil {
+		t.Fatalf("PoolSimulator.Collect(): %s", err)
+	}
+
+	if amount0.Sign() != 0 || amount1.Sign() != 0 {
+		t.Errorf("PoolSimulator.Collect() = %d, %d; want 0, 0", amount0, amount1)
+	}
+
+	amount0, amount1, err = sim.Collect("bob", tickLower, tickUpper, maxRequested, maxRequested)
+	if err != nil {
+		t.Fatalf("PoolSimulator.Collect(): %s", err)
+	}
+
+	if amount0.Sign() != 0 || amount1.Sign() != 0 {
+		t.Errorf("PoolSimulator.Collect() = %d, %d; want 0, 0", amount0, amount1)
+	}
+
+	// the requests are uint128 in the pool
+	if _, _, err := sim.Collect("alice", tickLower, tickUpper, big.NewInt(-5), big.NewInt(0)); !errors.Is(err, ErrAmountRequestedInvalid) {
+		t.Errorf("PoolSimulator.Collect() with a negative request error = %v; want %v", err, ErrAmountRequestedInvalid)
+	}
+}
+
+func TestPoolSimulatorCollectProtocol(t *testing.T) {
+	sim := newTestPoolSimulator()
+	sim.ProtocolFees.Token0.SetInt64(1000)
+	sim.ProtocolFees.Token1.SetInt64(2000)
+
+	amount0, amount1, err := sim.CollectProtocol(big.NewInt(400), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("PoolSimulator.CollectProtocol(): %s", err)
+	}
+
+	if amount0.Int64() != 400 || amount1.Int64() != 0 {
+		t.Errorf("PoolSimulator.CollectProtocol() = %d, %d; want 400, 0", amount0, amount1)
+	}
+
+	maxRequested := GetMaxValue(128)
+	amount0, amount1, err = sim.CollectProtocol(maxRequested, maxRequested)
+	if err != nil {
+		t.Fatalf("PoolSimulator.CollectProtocol(): %s", err)
+	}
+
+	if amount0.Int64() != 599 || amount1.Int64() != 1999 {
+		t.Errorf("PoolSimulator.CollectProtocol() = %d, %d; want 599, 1999", amount0, amount1)
+	}
+
+	if sim.ProtocolFees.Token0.Int64() != 1 || sim.ProtocolFees.Token1.Int64() != 1 {
+		t.Errorf("PoolSimulator.ProtocolFees = %d, %d; want 1, 1", sim.ProtocolFees.Token0, sim.ProtocolFees.Token1)
+	}
+
+	if _, _, err := sim.CollectProtocol(big.NewInt(0), big.NewInt(-5)); !errors.Is(err, ErrAmountRequestedInvalid) {
+		t.Errorf("PoolSimulator.CollectProtocol() with a negative request error = %v; want %v", err, ErrAmountRequestedInvalid)
+	}
+
+	if sim.ProtocolFees.Token0.Int64() != 1 || sim.ProtocolFees.Token1.Int64() != 1 {
+		t.Errorf("PoolSimulator.ProtocolFees = %d, %d; want 1, 1", sim.ProtocolFees.Token0, sim.ProtocolFees.Token1)
+	}
+}
+
+func TestPoolSimulatorSwapFeeGrowth(t *testing.T) {
+	sim := newTestPoolSimulator()
+
+	liquidity := big.NewInt(0).Set(sim.Slot0.Liquidity)
+	res := swapOrFatal(t, sim, true, big.NewInt(1000000000000000), big.NewInt(0))
+
+	refFeeGrowth := MulDiv(res.FeeTotal, Q128, liquidity)
+	if res.FeeGrowthGlobalX128Delta.Cmp(refFeeGrowth) != 0 {
+		t.Errorf("SwapResult.FeeGrowthGlobalX128Delta = %d; want %d", res.FeeGrowthGlobalX128Delta, refFeeGrowth)
+	}
+
+	if sim.Slot0.FeeGrowthGlobal0X128.Cmp(refFeeGrowth) != 0 || sim.Slot0.FeeGrowthGlobal1X128.Sign() != 0 {
+		t.Errorf("PoolSimulator.Slot0.FeeGrowthGlobalX128 = %d, %d; want %d, 0",
+			sim.Slot0.FeeGrowthGlobal0X128, sim.Slot0.FeeGrowthGlobal1X128, refFeeGrowth)
+	}
+
+	// cross the tick -600 and accumulate fees below it
+	amountSpecified, _ := big.NewInt(0).SetString("1000000000000000000000", 10)
+	swapOrFatal(t, sim, true, amountSpecified, GetSqrtRatioAtTick(big.NewInt(-900)))
+
+	tickLower := big.NewInt(-600)
+	tickUpper := big.NewInt(600)
+	crossed := sim.Ticks.Ticks[tickLower.Int64()].FeeGrowthOutside0X128.Val
+
+	if crossed.Sign() <= 0 || crossed.Cmp(sim.Slot0.FeeGrowthGlobal0X128) >= 0 {
+		t.Errorf("tick %d FeeGrowthOutside0X128 = %d; want in (0, %d)", tickLower, crossed, sim.Slot0.FeeGrowthGlobal0X128)
+	}
+
+	inside0, inside1 := sim.Ticks.getFeeGrowthInside(tickLower, tickUpper, sim.Slot0.TickCurrent,
+		sim.Slot0.FeeGrowthGlobal0X128, sim.Slot0.FeeGrowthGlobal1X128)
+
+	if inside0.Cmp(crossed) != 0 || inside1.Sign() != 0 {
+		t.Errorf("getFeeGrowthInside(%d, %d) = %d, %d; want %d, 0", tickLower, tickUpper, inside0, inside1, crossed)
+	}
+
+	inside0, _ = sim.Ticks.getFeeGrowthInside(big.NewInt(-1200), big.NewInt(1200), sim.Slot0.TickCurrent,
+		sim.Slot0.FeeGrowthGlobal0X128, sim.Slot0.FeeGrowthGlobal1X128)
+
+	if inside0.Cmp(sim.Slot0.FeeGrowthGlobal0X128) != 0 {
+		t.Errorf("getFeeGrowthInside(%d, %d) = %d; want %d", -1200, 1200, inside0, sim.Slot0.FeeGrowthGlobal0X128)
+	}
+
+	// cross the tick -600 back, fees paid in token1 are accumulated in both ranges
+	swapOrFatal(t, sim, false, amountSpecified, GetSqrtRatioAtTick(big.NewInt(0)))
+
+	inside0, inside1 = sim.Ticks.getFeeGrowthInside(tickLower, tickUpper, sim.Slot0.TickCurrent,
+		sim.Slot0.FeeGrowthGlobal0X128, sim.Slot0.FeeGrowthGlobal1X128)
+
+	if inside0.Cmp(crossed) != 0 {
+		t.Errorf("getFeeGrowthInside(%d, %d) = %d; want %d", tickLower, tickUpper, inside0, crossed)
+	}
+
+	if inside1.Sign() <= 0 || inside1.Cmp(sim.Slot0.FeeGrowthGlobal1X128) >= 0 {
+		t.Errorf("getFeeGrowthInside(%d, %d) = %d; want in (0, %d)", tickLower, tickUpper, inside1, sim.Slot0.FeeGrowthGlobal1X128)
+	}
+}
+
+func TestPoolSimulatorSetFeeProtocol(t *testing.T) {
+	sim := newTestPoolSimulator()
+
+	invalid := [][2]int64{{1, 0}, {0, 3}, {11, 4}, {4, 16}}
+
+	for _, c := range invalid {
+		if err := sim.SetFeeProtocol(big.NewInt(c[0]), big.NewInt(c[1])); !errors.Is(err, ErrFeeProtocolInvalid) {
+			t.Errorf("PoolSimulator.SetFeeProtocol(%d, %d) error = %v; want %v", c[0], c[1], err, ErrFeeProtocolInvalid)
+		}
+	}
+
+	if err := sim.SetFeeProtocol(big.NewInt(4), big.NewInt(10)); err != nil {
+		t.Fatalf("PoolSimulator.SetFeeProtocol(): %s", err)
+	}
+
+	if sim.Slot0.FeeProtocol.Int64() != 4+10<<4 {
+		t.Errorf("PoolSimulator.Slot0.FeeProtocol = %d; want %d", sim.Slot0.FeeProtocol, 4+10<<4)
+	}
+
+	liquidity := big.NewInt(0).Set(sim.Slot0.Liquidity)
+	amountSpecified := big.NewInt(1000000000000000)
+	res := swapOrFatal(t, sim, true, amountSpecified, big.NewInt(0))
+	fee, protocolFee := res.FeeTotal, res.ProtocolFee
+
+	refProtocolFee := big.NewInt(0).Div(fee, big.NewInt(4))
+	if protocolFee.Cmp(refProtocolFee) != 0 || sim.ProtocolFees.Token0.Cmp(refProtocolFee) != 0 {
+		t.Errorf("PoolSimulator.Swap() protocol fee = %d, accumulated %d; want %d",
+			protocolFee, sim.ProtocolFees.Token0, refProtocolFee)
+	}
+
+	refFeeGrowth := MulDiv(big.NewInt(0).Sub(fee, protocolFee), Q128, liquidity)
+	if sim.Slot0.FeeGrowthGlobal0X128.Cmp(refFeeGrowth) != 0 {
+		t.Errorf("PoolSimulator.Slot0.FeeGrowthGlobal0X128 = %d; want %d", sim.Slot0.FeeGrowthGlobal0X128, refFeeGrowth)
+	}
+
+	res = swapOrFatal(t, sim, false, amountSpecified, big.NewInt(0))
+	fee, protocolFee = res.FeeTotal, res.ProtocolFee
+
+	refProtocolFee = big.NewInt(0).Div(fee, big.NewInt(10))
+	if protocolFee.Cmp(refProtocolFee) != 0 || sim.ProtocolFees.Token1.Cmp(refProtocolFee) != 0 {
+		t.Errorf("PoolSimulator.Swap() protocol fee = %d, accumulated %d; want %d",
+			protocolFee, sim.ProtocolFees.Token1, refProtocolFee)
+	}
+
+	pool, ticks := newTestPool()
+	pool.FeeProtocol = BigInt{Val: big.NewInt(0).Set(sim.Slot0.FeeProtocol)}
+
+	res, err := DoSwap(true, amountSpecified, big.NewInt(0), NewTickStorage(ticks, big.NewInt(60)), pool)
+
+	if err != nil {
+		t.Fatalf("DoSwap(): %s", err)
+	}
+
+	if res.ProtocolFee.Sign() <= 0 {
+		t.Errorf("DoSwap() protocol fee = %d; want positive", res.ProtocolFee)
+	}
+}
+
+// simulatorState returns the JSON snapshot of the simulator to compare the states
+func simulatorState(t *testing.T, sim *PoolSimulator) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := SavePoolSnapshot(&buf, sim.Snapshot("", big.NewInt(0)), SnapshotJSON); err != nil {
+		t.Fatalf("SavePoolSnapshot(): %s", err)
+	}
+	return buf.String()
+}
+
+func TestPoolSimulatorFork(t *testing.T) {
+	sim := newTestPoolSimulator()
+	tickLower, tickUpper := big.NewInt(-60), big.NewInt(60)
+	liquidity := big.NewInt(1e18)
+
+	if _, _, err := sim.Mint("alice", tickLower, tickUpper, liquidity); err != nil {
+		t.Fatalf("PoolSimulator.Mint(): %s", err)
+	}
+	swapOrFatal(t, sim, true, big.NewInt(1e15), big.NewInt(0))
+
+	// the branch runs on the fork and on the deep copy of the state
+	branch := func(sim *PoolSimulator) {
+		swapOrFatal(t, sim, true, big.NewInt(4e18), big.NewInt(0))
+
+		if _, _, err := sim.Mint("bob", big.NewInt(-1200), big.NewInt(-600), liquidity); err != nil {
+			t.Fatalf("PoolSimulator.Mint(): %s", err)
+		}
+		if _, _, err := sim.Burn("alice", tickLower, tickUpper, big.NewInt(1e17)); err != nil {
+			t.Fatalf("PoolSimulator.Burn(): %s", err)
+		}
+
+		swapOrFatal(t, sim, false, big.NewInt(5e18), big.NewInt(0))
+		if _, _, err := sim.Collect("alice", tickLower, tickUpper, MAX_UINT_128, MAX_UINT_128); err != nil {
+			t.Fatalf("PoolSimulator.Collect(): %s", err)
+		}
+	}
+
+	base := simulatorState(t, sim)
+	fork := sim.Fork()
+	ref := sim.Snapshot("", big.NewInt(0)).Simulator()
+
+	branch(fork)
+	branch(ref)
+
+	if simulatorState(t, sim) != base {
+		t.Errorf("PoolSimulator.Fork() changes of the fork leaked into the parent")
+	}
+
+	want := simulatorState(t, ref)
+	if simulatorState(t, fork) != want {
+		t.Errorf("PoolSimulator.Fork() state = %s; want %s", simulatorState(t, fork), want)
+	}
+
+	// the changes of the parent and of the fork of the fork do not leak into the fork
+	branch(sim)
+	branch(fork.Fork())
+
+	if simulatorState(t, fork) != want {
+		t.Errorf("PoolSimulator.Fork() changes of the parent leaked into the fork")
+	}
+
+	if simulatorState(t, sim) != want {
+		t.Errorf("PoolSimulator state after the branch = %s; want %s", simulatorState(t, sim), want)
+	}
+}
+
+func TestPoolSimulatorBitmap(t *testing.T) {
+	sim := newTestPoolSimulator()
+	ref := newTestPoolSimulator()
+	ref.Bitmap = nil
+
+	// the simulators step through the bitmap by default
+	for _, s := range []*PoolSimulator{sim, sim.Snapshot("", big.NewInt(0)).Simulator()} {
+		if s.Bitmap == nil || s.Bitmap.Ticks != s.Ticks || !s.Bitmap.IsInitialized(big.NewInt(-1200)) {
+			t.Errorf("PoolSimulator.Bitmap = %+v; want the bitmap of the ticks", s.Bitmap)
+		}
+	}
+
+	// the ticks in the second word to the left, the swaps step through the empty word between them
+	tickLower, tickUpper := big.NewInt(-2*256*60), big.NewInt(-256*60-60)
+	liquidity := big.NewInt(1e18)
+
+	for _, s := range []*PoolSimulator{sim, ref} {
+		if _, _, err := s.Mint("alice", tickLower, tickUpper, liquidity); err != nil {
+			t.Fatalf("PoolSimulator.Mint(): %s", err)
+		}
+	}
+
+	if !sim.Bitmap.IsInitialized(tickLower) || !sim.Bitmap.IsInitialized(tickUpper) {
+		t.Errorf("PoolSimulator.Mint() bitmap = %t, %t; want the ticks flipped",
+			sim.Bitmap.IsInitialized(tickLower), sim.Bitmap.IsInitialized(tickUpper))
+	}
+
+	fork := sim.Fork()
+
+	if _, _, err := fork.Burn("alice", tickLower, tickUpper, liquidity); err != nil {
+		t.Fatalf("PoolSimulator.Burn(): %s", err)
+	}
+
+	if fork.Bitmap.IsInitialized(tickLower) || fork.Bitmap.IsInitialized(tickUpper) {
+		t.Errorf("PoolSimulator.Burn() bitmap = %t, %t; want the ticks flipped back",
+			fork.Bitmap.IsInitialized(tickLower), fork.Bitmap.IsInitialized(tickUpper))
+	}
+
+	if !sim.Bitmap.IsInitialized(tickLower) || sim.Bitmap.Ticks != sim.Ticks || fork.Bitmap.Ticks != fork.Ticks {
+		t.Errorf("PoolSimulator.Fork() bitmap is shared with the parent")
+	}
+
+	// the swap down stops at the word boundaries but crosses the same ticks
+	res := swapOrFatal(t, sim, true, big.NewInt(9e18), big.NewInt(0))
+	want := swapOrFatal(t, ref, true, big.NewInt(9e18), big.NewInt(0))
+
+	if len(res.TicksCrossed) != len(want.TicksCrossed) || res.Tick.Cmp(want.Tick) != 0 {
+		t.Errorf("PoolSimulator.Swap() with Bitmap = %d ticks crossed, tick %d; want %d, %d",
+			len(res.TicksCrossed), res.Tick, len(want.TicksCrossed), want.Tick)
+	}
+}
@@ -0,0 +1,264 @@
+package uniswap_core
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+)
+
+// PoolSnapshotVersion is the version of the snapshot format written by SavePoolSnapshot
+const PoolSnapshotVersion = 1
+
+// State of an initialized tick, the fields of Tick.Info
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/Tick.sol
+type TickSnapshot struct {
+	TickIdx *big.Int
+	// the total position liquidity that references this tick
+	LiquidityGross *big.Int
+	// amount of net liquidity added (subtracted) when tick is crossed from left to right (right to left)
+	LiquidityNet *big.Int
+	// fee growth per unit of liquidity on the other side of this tick (relative to the current tick)
+	FeeGrowthOutside0X128 *big.Int
+	FeeGrowthOutside1X128 *big.Int
+}
+
+func newTickSnapshot(tick *Tick) TickSnapshot {
+	return TickSnapshot{
+		TickIdx:               big.NewInt(0).Set(tick.TickIdx.Val),
+		LiquidityGross:        big.NewInt(0).Set(tick.LiquidityGross.Val),
+		LiquidityNet:          big.NewInt(0).Set(tick.LiquidityNet.Val),
+		FeeGrowthOutside0X128: big.NewInt(0).Set(tick.FeeGrowthOutside0X128.Val),
+		FeeGrowthOutside1X128: big.NewInt(0).Set(tick.FeeGrowthOutside1X128.Val)}
+}
+
+// Tick returns the tick in the format of the subgraph
+func (t *TickSnapshot) Tick() Tick {
+	tick := newTick(t.TickIdx)
+	tick.LiquidityGross.Val.Set(t.LiquidityGross)
+	tick.LiquidityNet.Val.Set(t.LiquidityNet)
+	tick.FeeGrowthOutside0X128.Val.Set(t.FeeGrowthOutside0X128)
+	tick.FeeGrowthOutside1X128.Val.Set(t.FeeGrowthOutside1X128)
+	return *tick
+}
+
+// PoolSnapshot is the state of a pool at the given block.
+// The ticks are ordered by index and the positions like PositionStore.All does,
+// so the same state is always saved into the same bytes
+type PoolSnapshot struct {
+	Version      int
+	PoolId       string
+	BlockNumber  *big.Int
+	Slot0        *Slot0
+	ProtocolFees *ProtocolFees
+	Ticks        []TickSnapshot
+	Positions    *PositionStore
+	// the observations array of the oracle, see PoolSimulator.Observations
+	Observations []Observation
+}
+
+type SnapshotFormat int
+
+const (
+	// indented JSON
+	SnapshotJSON SnapshotFormat = iota
+	// gzip-compressed JSON, the same document as SnapshotJSON
+	SnapshotGzip
+)
+
+// Snapshot returns a deep copy of the simulator's state
+func (p *PoolSimulator) Snapshot(poolId string, blockNumber *big.Int) *PoolSnapshot {
+	s := &PoolSnapshot{
+		Version:      PoolSnapshotVersion,
+		PoolId:       poolId,
+		BlockNumber:  big.NewInt(0).Set(blockNumber),
+		Slot0:        p.Slot0.Copy(),
+		ProtocolFees: NewProtocolFees(),
+		Ticks:        make([]TickSnapshot, 0, len(p.Ticks.Ticks)),
+		Positions:    NewPositionStore(),
+		Observations: copyObservations(p.Observations)}
+
+	s.ProtocolFees.Token0.Set(p.ProtocolFees.Token0)
+	s.ProtocolFees.Token1.Set(p.ProtocolFees.Token1)
+
+	for _, tick := range p.Ticks.Ticks {
+		s.Ticks = append(s.Ticks, newTickSnapshot(tick))
+	}
+	sortTicks(s.Ticks)
+
+	for _, pos := range p.Positions.All() {
+		s.Positions.Put(pos.Copy())
+	}
+
+	return s
+}
+
+// Simulator returns a simulator owning a deep copy of the snapshot's state, the tick bitmap is rebuilt from the ticks
+func (s *PoolSnapshot) Simulator() *PoolSimulator {
+	ticks := make([]Tick, len(s.Ticks))
+	for i := range s.Ticks {
+		ticks[i] = s.Ticks[i].Tick()
+	}
+
+	p := &PoolSimulator{
+		Slot0:        s.Slot0.Copy(),
+		Ticks:        NewTickStorage(ticks, s.Slot0.TickSpacing),
+		ProtocolFees: NewProtocolFees(),
+		Positions:    NewPositionStore(),
+		Observations: copyObservations(s.Observations)}
+	p.Bitmap = NewTickBitmap(p.Ticks)
+
+	p.ProtocolFees.Token0.Set(s.ProtocolFees.Token0)
+	p.ProtocolFees.Token1.Set(s.ProtocolFees.Token1)
+
+	for _, pos := range s.Positions.All() {
+		p.Positions.Put(pos.Copy())
+	}
+
+	return p
+}
+
+func sortTicks(ticks []TickSnapshot) {
+	sort.Slice(ticks, func(i, j int) bool {
+		return ticks[i].TickIdx.Cmp(ticks[j].TickIdx) < 0
+	})
+}
+
+// SavePoolSnapshot writes the snapshot in the given format, the version of the snapshot is set to PoolSnapshotVersion
+func SavePoolSnapshot(w io.Writer, snapshot *PoolSnapshot, format SnapshotFormat) error {
+	s := *snapshot
+	s.Version = PoolSnapshotVersion
+
+	if s.Positions == nil {
+		s.Positions = NewPositionStore()
+	}
+
+	s.Ticks = append([]TickSnapshot(nil), snapshot.Ticks...)
+	sortTicks(s.Ticks)
+
+	data, err := json.MarshalIndent(&s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	switch format {
+	case SnapshotJSON:
+		_, err = w.Write(data)
+		return err
+	case SnapshotGzip:
+		zw, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+		if err != nil {
+			return err
+		}
+
+		if _, err := zw.Write(data); err != nil {
+			return err
+		}
+		return zw.Close()
+	}
+
+	return fmt.Errorf("SavePoolSnapshot: unknown format %d", format)
+}
+
+// LoadPoolSnapshot reads the snapshot written by SavePoolSnapshot in any format
+// Returns ErrSnapshotInvalid if the version is unknown or the state is incomplete
+func LoadPoolSnapshot(r io.Reader) (*PoolSnapshot, error) {
+	br := bufio.NewReader(r)
+
+	var src io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		src = zr
+	}
+
+	s := new(PoolSnapshot)
+	if err := json.NewDecoder(src).Decode(s); err != nil {
+		return nil, err
+	}
+
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// validate checks the version and the required fields, the omitted amounts are set to zero
+func (s *PoolSnapshot) validate() error {
+	if s.Version != PoolSnapshotVersion {
+		return fmt.Errorf("%w: version %d, want %d", ErrSnapshotInvalid, s.Version, PoolSnapshotVersion)
+	}
+
+	if s.Slot0 == nil || s.Slot0.TickSpacing == nil || s.Slot0.TickSpacing.Sign() <= 0 {
+		return fmt.Errorf("%w: slot0 has no tick spacing", ErrSnapshotInvalid)
+	}
+
+	if s.Slot0.SqrtPriceX96 == nil || s.Slot0.TickCurrent == nil {
+		return fmt.Errorf("%w: slot0 has no price", ErrSnapshotInvalid)
+	}
+
+	zeroIfNil := func(fields ...**big.Int) {
+		for _, field := range fields {
+			if *field == nil {
+				*field = big.NewInt(0)
+			}
+		}
+	}
+
+	zeroIfNil(
+		&s.BlockNumber,
+		&s.Slot0.Fee,
+		&s.Slot0.Liquidity,
+		&s.Slot0.FeeGrowthGlobal0X128,
+		&s.Slot0.FeeGrowthGlobal1X128,
+		&s.Slot0.ObservationIndex,
+		&s.Slot0.ObservationCardinality,
+		&s.Slot0.ObservationCardinalityNext,
+		&s.Slot0.FeeProtocol)
+
+	if s.ProtocolFees == nil {
+		s.ProtocolFees = NewProtocolFees()
+	}
+	zeroIfNil(&s.ProtocolFees.Token0, &s.ProtocolFees.Token1)
+
+	for i := range s.Ticks {
+		tick := &s.Ticks[i]
+
+		if tick.TickIdx == nil {
+			return fmt.Errorf("%w: tick %d has no index", ErrSnapshotInvalid, i)
+		}
+
+		if i > 0 && tick.TickIdx.Cmp(s.Ticks[i-1].TickIdx) <= 0 {
+			return fmt.Errorf("%w: tick %d is not ordered", ErrSnapshotInvalid, tick.TickIdx)
+		}
+
+		zeroIfNil(
+			&tick.LiquidityGross,
+			&tick.LiquidityNet,
+			&tick.FeeGrowthOutside0X128,
+			&tick.FeeGrowthOutside1X128)
+	}
+
+	if s.Positions == nil {
+		s.Positions = NewPositionStore()
+	}
+
+	if s.Observations == nil {
+		s.Observations = make([]Observation, 0)
+	}
+
+	for i := range s.Observations {
+		o := &s.Observations[i]
+		zeroIfNil(&o.BlockTimestamp, &o.TickCumulative, &o.SecondsPerLiquidityCumulativeX128)
+	}
+
+	return nil
+}
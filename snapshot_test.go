@@ -0,0 +1,143 @@
+package uniswap_core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func newTestSnapshot(t *testing.T) (*PoolSimulator, *PoolSnapshot) {
+	t.Helper()
+
+	sim := newTestPoolSimulator()
+	if err := sim.SetFeeProtocol(big.NewInt(4), big.NewInt(5)); err != nil {
+		t.Fatalf("PoolSimulator.SetFeeProtocol(): %s", err)
+	}
+
+	if _, _, err := sim.Mint("alice", big.NewInt(-60), big.NewInt(120), big.NewInt(1e15)); err != nil {
+		t.Fatalf("PoolSimulator.Mint(): %s", err)
+	}
+
+	swapOrFatal(t, sim, true, big.NewInt(1e16), big.NewInt(0))
+	swapOrFatal(t, sim, false, big.NewInt(-3e15), big.NewInt(0))
+
+	// the oracle is read from the pool contract
+	sim.Observations = append(sim.Observations, Observation{
+		BlockTimestamp:                    big.NewInt(1640995200),
+		TickCumulative:                    big.NewInt(-123456789),
+		SecondsPerLiquidityCumulativeX128: big.NewInt(0).Lsh(big.NewInt(3), 100),
+		Initialized:                       true})
+
+	snapshot := sim.Snapshot("0x8ad599c3a0ff1de082011efddc58f1908eb6e6d8", big.NewInt(14000000))
+	return sim, snapshot
+}
+
+func TestPoolSnapshotSaveLoad(t *testing.T) {
+	sim, snapshot := newTestSnapshot(t)
+
+	for _, format := range []SnapshotFormat{SnapshotJSON, SnapshotGzip} {
+		var buf bytes.Buffer
+		if err := SavePoolSnapshot(&buf, snapshot, format); err != nil {
+			t.Fatalf("SavePoolSnapshot(%d): %s", format, err)
+		}
+		data := buf.Bytes()
+
+		loaded, err := LoadPoolSnapshot(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("LoadPoolSnapshot(%d): %s", format, err)
+		}
+
+		// the same state is saved into the same bytes
+		var again bytes.Buffer
+		if err := SavePoolSnapshot(&again, loaded, format); err != nil {
+			t.Fatalf("SavePoolSnapshot(%d): %s", format, err)
+		}
+
+		if !bytes.Equal(data, again.Bytes()) {
+			t.Errorf("SavePoolSnapshot(%d) of the loaded snapshot differs from the saved one", format)
+		}
+
+		if loaded.Version != PoolSnapshotVersion || loaded.PoolId != snapshot.PoolId || loaded.BlockNumber.Cmp(snapshot.BlockNumber) != 0 {
+			t.Errorf("LoadPoolSnapshot(%d) = %d, %s, %d; want %d, %s, %d", format,
+				loaded.Version, loaded.PoolId, loaded.BlockNumber, PoolSnapshotVersion, snapshot.PoolId, snapshot.BlockNumber)
+		}
+
+		if len(loaded.Observations) != 1 || loaded.Observations[0].TickCumulative.Cmp(big.NewInt(-123456789)) != 0 {
+			t.Errorf("LoadPoolSnapshot(%d) observations = %+v; want %+v", format, loaded.Observations, snapshot.Observations)
+		}
+
+		// the restored simulator continues like the original one
+		restored := loaded.Simulator()
+
+		if len(restored.Observations) != 1 || restored.Observations[0].SecondsPerLiquidityCumulativeX128.Cmp(
+			sim.Observations[0].SecondsPerLiquidityCumulativeX128) != 0 || !restored.Observations[0].Initialized {
+			t.Errorf("PoolSnapshot.Simulator() observations = %+v; want %+v", restored.Observations, sim.Observations)
+		}
+
+		want := swapOrFatal(t, sim.Snapshot("", big.NewInt(0)).Simulator(), true, big.NewInt(2e18), big.NewInt(0))
+		res := swapOrFatal(t, restored, true, big.NewInt(2e18), big.NewInt(0))
+
+		if !swapResultsEqual(res, want) {
+			t.Errorf("PoolSimulator.Swap() after LoadPoolSnapshot(%d) = %+v; want %+v", format, res, want)
+		}
+
+		if restored.ProtocolFees.Token0.Cmp(big.NewInt(0).Add(sim.ProtocolFees.Token0, want.ProtocolFee)) != 0 {
+			t.Errorf("PoolSimulator.ProtocolFees.Token0 = %d; want %d", restored.ProtocolFees.Token0,
+				big.NewInt(0).Add(sim.ProtocolFees.Token0, want.ProtocolFee))
+		}
+
+		pos := restored.Positions.Get("alice", big.NewInt(-60), big.NewInt(120))
+		if pos == nil || pos.Liquidity.Cmp(big.NewInt(1e15)) != 0 {
+			t.Errorf("PositionStore.Get(alice) = %+v; want liquidity %d", pos, int64(1e15))
+		}
+	}
+}
+
+func TestPoolSnapshotCopy(t *testing.T) {
+	sim, snapshot := newTestSnapshot(t)
+	sqrtPriceX96 := big.NewInt(0).Set(snapshot.Slot0.SqrtPriceX96)
+
+	// the snapshot does not share the state with the simulator
+	swapOrFatal(t, sim, true, big.NewInt(1e17), big.NewInt(0))
+
+	if snapshot.Slot0.SqrtPriceX96.Cmp(sqrtPriceX96) != 0 {
+		t.Errorf("PoolSnapshot.Slot0.SqrtPriceX96 = %d; want %d", snapshot.Slot0.SqrtPriceX96, sqrtPriceX96)
+	}
+
+	sim.Observations[0].TickCumulative.SetInt64(0)
+	if snapshot.Observations[0].TickCumulative.Int64() != -123456789 {
+		t.Errorf("PoolSnapshot.Observations[0].TickCumulative = %d; want -123456789", snapshot.Observations[0].TickCumulative)
+	}
+
+	restored := snapshot.Simulator()
+	if _, _, err := restored.Burn("alice", big.NewInt(-60), big.NewInt(120), big.NewInt(1e15)); err != nil {
+		t.Fatalf("PoolSimulator.Burn(): %s", err)
+	}
+
+	if pos := snapshot.Positions.Get("alice", big.NewInt(-60), big.NewInt(120)); pos.Liquidity.Cmp(big.NewInt(1e15)) != 0 {
+		t.Errorf("PoolSnapshot position liquidity = %d; want %d", pos.Liquidity, int64(1e15))
+	}
+}
+
+func TestLoadPoolSnapshotInvalid(t *testing.T) {
+	_, snapshot := newTestSnapshot(t)
+
+	var buf bytes.Buffer
+	if err := SavePoolSnapshot(&buf, snapshot, SnapshotJSON); err != nil {
+		t.Fatalf("SavePoolSnapshot(): %s", err)
+	}
+
+	cases := []string{
+		strings.Replace(buf.String(), `"Version": 1`, `"Version": 2`, 1),
+		strings.Replace(buf.String(), `"TickSpacing": 60`, `"TickSpacing": 0`, 1),
+		`{"Version": 1}`,
+	}
+
+	for _, c := range cases {
+		if _, err := LoadPoolSnapshot(strings.NewReader(c)); !errors.Is(err, ErrSnapshotInvalid) {
+			t.Errorf("LoadPoolSnapshot() error = %v; want %v", err, ErrSnapshotInvalid)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package uniswap_core
 
 import (
+	"fmt"
 	"math/big"
 )
 
@@ -49,18 +50,41 @@ type SwapState struct {
 	protocolFee *big.Int
 	// the current liquidity in range
 	liquidity *big.Int
+	// the initialized ticks crossed by the swap
+	ticksCrossed []CrossedTick
+	// whether the bit widths of the contract are enforced, see TryMulDiv
+	strict bool
 }
 
-func (state *SwapState) UpdateTickLiquidity(zeroForOne bool, step *StepComputations, ticker TickReader) {
+// the initialized tick crossed by a swap
+type CrossedTick struct {
+	Tick *big.Int
+	// the liquidity added (subtracted) when the tick is crossed from left to right (right to left)
+	LiquidityNet *big.Int
+	// the global fee growth of the input token at the moment of crossing
+	FeeGrowthGlobalX128 *big.Int
+}
+
+func (state *SwapState) UpdateTickLiquidity(zeroForOne bool, step *StepComputations, ticker TickReader) error {
 	if state.sqrtPriceX96.Cmp(step.sqrtPriceNextX96) == 0 {
 		if step.initialized {
 			liquidityNet := ticker.GetLiquidityNet(step.tickNext)
 
+			state.ticksCrossed = append(state.ticksCrossed, CrossedTick{
+				Tick:                big.NewInt(0).Set(step.tickNext),
+				LiquidityNet:        big.NewInt(0).Set(liquidityNet),
+				FeeGrowthGlobalX128: big.NewInt(0).Set(state.feeGrowthGlobalX128)})
+
+			// the value belongs to the ticker
 			if zeroForOne {
-				liquidityNet.Neg(liquidityNet)
+				liquidityNet = big.NewInt(0).Neg(liquidityNet)
 			}
 
-			state.liquidity.Set(AddLiquidityDelta(state.liquidity, liquidityNet))
+			liquidity, err := TryAddLiquidityDelta(state.liquidity, liquidityNet, state.strict)
+			if err != nil {
+				return err
+			}
+			state.liquidity.Set(liquidity)
 		}
 
 		if zeroForOne {
@@ -70,11 +94,33 @@ func (state *SwapState) UpdateTickLiquidity(zeroForOne bool, step *StepComputati
 		}
 
 	} else if state.sqrtPriceX96.Cmp(step.sqrtPriceStartX96) != 0 {
-		state.tick.Set(GetTickAtSqrtRatio(state.sqrtPriceX96))
+		// recompute unless we're on a lower tick boundary (i.e. already transitioned ticks), and haven't moved
+		tick, err := TryGetTickAtSqrtRatio(state.sqrtPriceX96)
+		if err != nil {
+			return err
+		}
+		state.tick.Set(tick)
+	}
+
+	return nil
+}
+
+// update global fee tracker
+func (state *SwapState) UpdateFeeGrowthGlobal(step *StepComputations) error {
+	if state.liquidity.Cmp(ZERO_UINT_256) > 0 {
+		feeGrowthDelta, err := TryMulDiv(step.feeAmount, Q128, state.liquidity, state.strict)
+		if err != nil {
+			return err
+		}
+
+		state.feeGrowthGlobalX128.Add(state.feeGrowthGlobalX128, feeGrowthDelta)
+		state.feeGrowthGlobalX128.And(state.feeGrowthGlobalX128, MAX_UINT_256)
 	}
+	return nil
 }
 
-func (state *SwapState) UpdateAmount(exactInput bool, step *StepComputations) {
+// If strict the amounts must fit int256 like in the checked arithmetic of the contract
+func (state *SwapState) UpdateAmount(exactInput bool, step *StepComputations) error {
 	if exactInput {
 		state.amountSpecifiedRemaining.Sub(state.amountSpecifiedRemaining, step.amountIn)
 		state.amountSpecifiedRemaining.Sub(state.amountSpecifiedRemaining, step.feeAmount)
@@ -84,19 +130,52 @@ func (state *SwapState) UpdateAmount(exactInput bool, step *StepComputations) {
 		state.amountCalculated.Add(state.amountCalculated, step.amountIn)
 		state.amountCalculated.Add(state.amountCalculated, step.feeAmount)
 	}
+
+	if state.strict {
+		if err := checkInt256(state.amountSpecifiedRemaining); err != nil {
+			return err
+		}
+		return checkInt256(state.amountCalculated)
+	}
+	return nil
 }
 
-func NewSwapState(amountSpecified *big.Int, slot0 *Slot0, cache *SwapCache) *SwapState {
+func NewSwapState(zeroForOne bool, amountSpecified *big.Int, slot0 *Slot0, cache *SwapCache) *SwapState {
+	feeGrowthGlobalX128 := big.NewInt(0)
+	if zeroForOne {
+		feeGrowthGlobalX128.Set(slot0.FeeGrowthGlobal0X128)
+	} else {
+		feeGrowthGlobalX128.Set(slot0.FeeGrowthGlobal1X128)
+	}
+
 	return &SwapState{
 		amountSpecifiedRemaining: big.NewInt(0).Set(amountSpecified),
 		amountCalculated:         big.NewInt(0),
 		sqrtPriceX96:             big.NewInt(0).Set(slot0.SqrtPriceX96),
 		tick:                     big.NewInt(0).Set(slot0.TickCurrent),
-		feeGrowthGlobalX128:      big.NewInt(0),
+		feeGrowthGlobalX128:      feeGrowthGlobalX128,
 		protocolFee:              big.NewInt(0),
 		liquidity:                big.NewInt(0).Set(cache.liquidityStart)}
 }
 
+// Amounts returns the deltas of the pool balances of token0 and token1 once the swap loop is over
+func (state *SwapState) Amounts(zeroForOne bool, amountSpecified *big.Int) (amount0 *big.Int, amount1 *big.Int) {
+	exactInput := amountSpecified.Cmp(ZERO_UINT_256) > 0
+
+	amount0 = big.NewInt(0)
+	amount1 = big.NewInt(0)
+
+	if zeroForOne == exactInput {
+		amount0.Sub(amountSpecified, state.amountSpecifiedRemaining)
+		amount1.Set(state.amountCalculated)
+	} else {
+		amount0.Set(state.amountCalculated)
+		amount1.Sub(amountSpecified, state.amountSpecifiedRemaining)
+	}
+
+	return
+}
+
 type StepComputations struct {
 	// the price at the beginning of the step
 	sqrtPriceStartX96 *big.Int
@@ -125,11 +204,13 @@ func NewStepComputations() *StepComputations {
 		feeAmount:         big.NewInt(0)}
 }
 
+// ensure that we do not overshoot the min/max tick, as the tick bitmap is not aware of these bounds.
+// The tick is replaced rather than changed, since it belongs to the ticker
 func (step *StepComputations) ApplyTickLimits() {
 	if step.tickNext.Cmp(MIN_TICK) < 0 {
-		step.tickNext.Set(MIN_TICK)
+		step.tickNext = MIN_TICK
 	} else if step.tickNext.Cmp(MAX_TICK) > 0 {
-		step.tickNext.Set(MAX_TICK)
+		step.tickNext = MAX_TICK
 	}
 }
 
@@ -141,8 +222,14 @@ func (step *StepComputations) UpdateSqrtPriceStartX96(state *SwapState) {
 	step.sqrtPriceStartX96 = state.sqrtPriceX96
 }
 
-func (step *StepComputations) CalcSqrtPriceNextX96() {
-	step.sqrtPriceNextX96.Set(GetSqrtRatioAtTick(step.tickNext))
+func (step *StepComputations) CalcSqrtPriceNextX96() error {
+	sqrtPriceNextX96, err := TryGetSqrtRatioAtTick(step.tickNext)
+	if err != nil {
+		return err
+	}
+
+	step.sqrtPriceNextX96.Set(sqrtPriceNextX96)
+	return nil
 }
 
 func (step *StepComputations) GetSqrtRatioTargetX96(
@@ -162,94 +249,219 @@ func (step *StepComputations) GetSqrtRatioTargetX96(
 }
 
 func (step *StepComputations) UpdateAmount(
-	zeroForOne bool, sqrtPriceLimitX96 *big.Int, state *SwapState, slot0 *Slot0) (sqrtPriceX96 *big.Int) {
+	zeroForOne bool, sqrtPriceLimitX96 *big.Int, state *SwapState, slot0 *Slot0) (sqrtPriceX96 *big.Int, err error) {
 	sqrtRatioTargetX96 := step.GetSqrtRatioTargetX96(zeroForOne, sqrtPriceLimitX96)
 
-	sqrtPriceX96, step.amountIn, step.amountOut, step.feeAmount = ComputeSwapStep(
-		state.sqrtPriceX96, sqrtRatioTargetX96, state.liquidity, state.amountSpecifiedRemaining, slot0.Fee)
+	sqrtPriceX96, step.amountIn, step.amountOut, step.feeAmount, err = TryComputeSwapStep(
+		state.sqrtPriceX96, sqrtRatioTargetX96, state.liquidity, state.amountSpecifiedRemaining, slot0.Fee, state.strict)
 
 	return
 }
 
-func setDefaultSqrtPriceLimitX96(zeroForOne bool, sqrtPriceLimitX96 *big.Int) *big.Int {
-	if sqrtPriceLimitX96.Cmp(ZERO_UINT_256) == 0 {
-		if zeroForOne {
-			sqrtPriceLimitX96.Set(MIN_SQRT_RATIO)
-			sqrtPriceLimitX96.Add(sqrtPriceLimitX96, ONE_UINT_256)
-		} else {
-			sqrtPriceLimitX96.Set(MAX_SQRT_RATIO)
-			sqrtPriceLimitX96.Sub(sqrtPriceLimitX96, ONE_UINT_256)
+// Returns the price limit of the swap, zero limit is replaced with the minimal/maximal possible price.
+// The limit must be strictly between MIN_SQRT_RATIO and MAX_SQRT_RATIO and on the swap side of the current price,
+// otherwise ErrPriceLimitInvalid is returned ('SPL'). The argument itself is never changed
+func checkSqrtPriceLimitX96(zeroForOne bool, sqrtPriceLimitX96 *big.Int, slot0 *Slot0) (*big.Int, error) {
+	limit := big.NewInt(0).Set(sqrtPriceLimitX96)
+
+	if zeroForOne {
+		if limit.Cmp(ZERO_UINT_256) == 0 {
+			limit.Add(MIN_SQRT_RATIO, ONE_UINT_256)
 		}
+
+		if limit.Cmp(slot0.SqrtPriceX96) >= 0 || limit.Cmp(MIN_SQRT_RATIO) <= 0 {
+			return nil, fmt.Errorf("%w: sqrtPriceLimitX96 %d must be in (%d, %d)",
+				ErrPriceLimitInvalid, sqrtPriceLimitX96, MIN_SQRT_RATIO, slot0.SqrtPriceX96)
+		}
+
+		return limit, nil
+	}
+
+	if limit.Cmp(ZERO_UINT_256) == 0 {
+		limit.Sub(MAX_SQRT_RATIO, ONE_UINT_256)
+	}
+
+	if limit.Cmp(slot0.SqrtPriceX96) <= 0 || limit.Cmp(MAX_SQRT_RATIO) >= 0 {
+		return nil, fmt.Errorf("%w: sqrtPriceLimitX96 %d must be in (%d, %d)",
+			ErrPriceLimitInvalid, sqrtPriceLimitX96, slot0.SqrtPriceX96, MAX_SQRT_RATIO)
 	}
 
-	return sqrtPriceLimitX96
+	return limit, nil
+}
+
+type SwapResult struct {
+	// The delta of the balance of token0 of the pool, exact when negative, minimum when positive
+	Amount0 *big.Int
+	// The delta of the balance of token1 of the pool, exact when negative, minimum when positive
+	Amount1 *big.Int
+	// the fee paid in the input token, including the protocol fee
+	FeeTotal *big.Int
+	// the part of the fee paid in the input token which is taken by the protocol
+	ProtocolFee *big.Int
+	// the price, tick and liquidity in range after the swap
+	SqrtPriceX96 *big.Int
+	Tick         *big.Int
+	Liquidity    *big.Int
+	// the growth of the global fee of the input token, per unit of liquidity
+	FeeGrowthGlobalX128Delta *big.Int
+	// the initialized ticks crossed by the swap in order of crossing
+	TicksCrossed []CrossedTick
+	// whether the swap stopped because the price reached sqrtPriceLimitX96
+	PriceLimitReached bool
 }
 
 // Swap token0 for token1, or token1 for token0
 // zeroForOne	bool	The direction of the swap, true for token0 to token1, false for token1 to token0
-// amountSpecified	big.Int	The amount of the swap, which implicitly configures the swap as exact input (positive), or exact output (negative)
-// sqrtPriceLimitX96	big.Int	The Q64.96 sqrt price limit. If zero for one, the price cannot be less than this
+// amountSpecified	big.Int	The amount of the swap, which implicitly configures the swap as exact input (positive), or exact output (negative), zero results in ErrAmountSpecifiedZero
+// sqrtPriceLimitX96	big.Int	The Q64.96 sqrt price limit. If zero for one, the price cannot be less than this,
+// zero means no limit. The limit on the wrong side of the current price results in ErrPriceLimitInvalid
 // ticker TickReader	tick bitmap object
 // slotReader PoolStateReader	Pool's state retriever object
-// amount0	big.Int	The delta of the balance of token0 of the pool, exact when negative, minimum when positive
-// amount1	big.Int	The delta of the balance of token1 of the pool, exact when negative, minimum when positive
+// Errors mirror the revert reasons of the pool, e.g. ErrPoolLocked, ErrTickOutOfRange, ErrPriceOutOfRange
+// or ErrInsufficientLiquidity. The amounts are unbounded, see DoSwapStrict
 func DoSwap(zeroForOne bool,
 	amountSpecified *big.Int,
 	sqrtPriceLimitX96 *big.Int,
 	ticker TickReader,
-	slotReader PoolStateReader) (amount0 *big.Int, amount1 *big.Int, feeTotal *big.Int) {
+	slotReader PoolStateReader) (*SwapResult, error) {
+	slot0, err := slotReader.CurrentState()
+	if err != nil {
+		return nil, err
+	}
+	return doSwap(zeroForOne, amountSpecified, sqrtPriceLimitX96, ticker, slot0, false)
+}
 
-	sqrtPriceLimitX96 = setDefaultSqrtPriceLimitX96(zeroForOne, sqrtPriceLimitX96)
+// DoSwapStrict is DoSwap which enforces the bit widths and the revert conditions of the contracts,
+// the values which do not fit the Solidity types result in ErrOverflow or ErrInsufficientLiquidity
+// like the reverts of the pool, see TryMulDiv
+func DoSwapStrict(zeroForOne bool,
+	amountSpecified *big.Int,
+	sqrtPriceLimitX96 *big.Int,
+	ticker TickReader,
+	slotReader PoolStateReader) (*SwapResult, error) {
+	slot0, err := slotReader.CurrentState()
+	if err != nil {
+		return nil, err
+	}
+	return doSwap(zeroForOne, amountSpecified, sqrtPriceLimitX96, ticker, slot0, true)
+}
+
+// doSwap runs the swap loop against the slot0 snapshot, slot0 itself is left untouched
+func doSwap(zeroForOne bool,
+	amountSpecified *big.Int,
+	sqrtPriceLimitX96 *big.Int,
+	ticker TickReader,
+	slot0 *Slot0,
+	strict bool) (*SwapResult, error) {
+
+	if amountSpecified.Sign() == 0 {
+		return nil, fmt.Errorf("%w: nothing to swap", ErrAmountSpecifiedZero)
+	}
+
+	if slot0.SqrtPriceX96.Cmp(ZERO_UINT_256) == 0 {
+		return nil, fmt.Errorf("%w: sqrtPriceX96 is not initialized", ErrPoolLocked)
+	}
+
+	sqrtPriceLimitX96, err := checkSqrtPriceLimitX96(zeroForOne, sqrtPriceLimitX96, slot0)
+	if err != nil {
+		return nil, err
+	}
 
-	feeTotal = big.NewInt(0)
+	if strict {
+		if err := checkInt256(amountSpecified); err != nil {
+			return nil, err
+		}
+	}
+
+	feeTotal := big.NewInt(0)
 	exactInput := amountSpecified.Cmp(ZERO_UINT_256) > 0
 
-	slot0 := slotReader.CurrentState()
 	cache := NewSwapCache(zeroForOne, slot0)
-	state := NewSwapState(amountSpecified, slot0, cache)
+	state := NewSwapState(zeroForOne, amountSpecified, slot0, cache)
+	state.strict = strict
 	step := NewStepComputations()
 
 	for state.amountSpecifiedRemaining.Cmp(ZERO_UINT_256) != 0 && state.sqrtPriceX96.Cmp(sqrtPriceLimitX96) != 0 {
 		step.UpdateSqrtPriceStartX96(state)
 		step.UpdateTickNext(zeroForOne, state, ticker)
 		step.ApplyTickLimits()
-		step.CalcSqrtPriceNextX96()
-		state.sqrtPriceX96 = step.UpdateAmount(zeroForOne, sqrtPriceLimitX96, state, slot0)
+
+		if err := step.CalcSqrtPriceNextX96(); err != nil {
+			return nil, err
+		}
+
+		sqrtPriceX96, err := step.UpdateAmount(zeroForOne, sqrtPriceLimitX96, state, slot0)
+		if err != nil {
+			return nil, err
+		}
+		state.sqrtPriceX96 = sqrtPriceX96
 
 		feeTotal.Add(feeTotal, step.feeAmount)
 
-		state.UpdateAmount(exactInput, step)
+		if err := state.UpdateAmount(exactInput, step); err != nil {
+			return nil, err
+		}
 
 		if cache.feeProtocol.Cmp(ZERO_UINT_256) > 0 {
 			delta := big.NewInt(0)
 			delta.Div(step.feeAmount, cache.feeProtocol)
 			step.feeAmount.Sub(step.feeAmount, delta)
-			state.protocolFee.Add(state.protocolFee, delta)
+			wrapUint128(state.protocolFee.Add(state.protocolFee, delta), strict)
 		}
 
-		state.UpdateTickLiquidity(zeroForOne, step, ticker)
-	}
+		if err := state.UpdateFeeGrowthGlobal(step); err != nil {
+			return nil, err
+		}
 
-	amount0 = big.NewInt(0)
-	amount1 = big.NewInt(0)
+		if err := state.UpdateTickLiquidity(zeroForOne, step, ticker); err != nil {
+			return nil, err
+		}
+	}
 
-	if zeroForOne == exactInput {
-		amount0.Sub(amountSpecified, state.amountSpecifiedRemaining)
-		amount1.Set(state.amountCalculated)
-	} else {
-		amount0.Set(state.amountCalculated)
-		amount1.Sub(amountSpecified, state.amountSpecifiedRemaining)
+	feeGrowthGlobalX128Start := slot0.FeeGrowthGlobal1X128
+	if zeroForOne {
+		feeGrowthGlobalX128Start = slot0.FeeGrowthGlobal0X128
 	}
 
-	return
+	result := &SwapResult{
+		FeeTotal:                 feeTotal,
+		ProtocolFee:              state.protocolFee,
+		SqrtPriceX96:             state.sqrtPriceX96,
+		Tick:                     state.tick,
+		Liquidity:                state.liquidity,
+		FeeGrowthGlobalX128Delta: SubUint256(state.feeGrowthGlobalX128, feeGrowthGlobalX128Start),
+		TicksCrossed:             state.ticksCrossed,
+		PriceLimitReached:        state.sqrtPriceX96.Cmp(sqrtPriceLimitX96) == 0}
+
+	result.Amount0, result.Amount1 = state.Amounts(zeroForOne, amountSpecified)
+	return result, nil
 }
 
+// Panics if the price cannot be computed, see TryComputeSwapStep
 func ComputeSwapStep(
 	sqrtRatioCurrentX96 *big.Int,
 	sqrtRatioTargetX96 *big.Int,
 	liquidity *big.Int,
 	amountRemaining *big.Int,
 	feePips *big.Int) (sqrtRatioNextX96 *big.Int, amountIn *big.Int, amountOut *big.Int, feeAmount *big.Int) {
+	var err error
+	sqrtRatioNextX96, amountIn, amountOut, feeAmount, err = TryComputeSwapStep(
+		sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, amountRemaining, feePips, false)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// TryComputeSwapStep is ComputeSwapStep which returns an error instead of panicking,
+// if strict the amounts and the price must fit the Solidity types, see TryMulDiv
+func TryComputeSwapStep(
+	sqrtRatioCurrentX96 *big.Int,
+	sqrtRatioTargetX96 *big.Int,
+	liquidity *big.Int,
+	amountRemaining *big.Int,
+	feePips *big.Int,
+	strict bool) (sqrtRatioNextX96 *big.Int, amountIn *big.Int, amountOut *big.Int, feeAmount *big.Int, err error) {
 
 	zeroForOne := sqrtRatioCurrentX96.Cmp(sqrtRatioTargetX96) >= 0
 	exactIn := amountRemaining.Cmp(ZERO_UINT_256) >= 0
@@ -263,40 +475,57 @@ func ComputeSwapStep(
 	oneSubFeePips.Sub(onex6, feePips)
 
 	if exactIn {
-		amountRemainingLessFee := MulDiv(amountRemaining, oneSubFeePips, onex6)
+		var amountRemainingLessFee *big.Int
+		if amountRemainingLessFee, err = TryMulDiv(amountRemaining, oneSubFeePips, onex6, strict); err != nil {
+			return
+		}
 
 		if zeroForOne {
-			amountIn = GetAmount0DeltaRoundingUp(sqrtRatioTargetX96, sqrtRatioCurrentX96, liquidity, true)
+			amountIn, err = TryGetAmount0DeltaRoundingUp(sqrtRatioTargetX96, sqrtRatioCurrentX96, liquidity, true, strict)
 		} else {
-			amountIn = GetAmount1DeltaRoundingUp(sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, true)
+			amountIn, err = TryGetAmount1DeltaRoundingUp(sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, true, strict)
+		}
+		if err != nil {
+			return
 		}
 
 		if amountRemainingLessFee.Cmp(amountIn) >= 0 {
 			sqrtRatioNextX96 = big.NewInt(0)
 			sqrtRatioNextX96.Set(sqrtRatioTargetX96)
 		} else {
-			sqrtRatioNextX96 = GetNextSqrtPriceFromInput(
+			sqrtRatioNextX96, err = TryGetNextSqrtPriceFromInput(
 				sqrtRatioCurrentX96,
 				liquidity,
 				amountRemainingLessFee,
-				zeroForOne)
+				zeroForOne,
+				strict)
+			if err != nil {
+				return
+			}
 		}
 	} else {
 		if zeroForOne {
-			amountOut = GetAmount1DeltaRoundingUp(sqrtRatioTargetX96, sqrtRatioCurrentX96, liquidity, false)
+			amountOut, err = TryGetAmount1DeltaRoundingUp(sqrtRatioTargetX96, sqrtRatioCurrentX96, liquidity, false, strict)
 		} else {
-			amountOut = GetAmount0DeltaRoundingUp(sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, false)
+			amountOut, err = TryGetAmount0DeltaRoundingUp(sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, false, strict)
+		}
+		if err != nil {
+			return
 		}
 
 		if absAmountRemaining.Cmp(amountOut) >= 0 {
 			sqrtRatioNextX96 = big.NewInt(0)
 			sqrtRatioNextX96.Set(sqrtRatioTargetX96)
 		} else {
-			sqrtRatioNextX96 = GetNextSqrtPriceFromOutput(
+			sqrtRatioNextX96, err = TryGetNextSqrtPriceFromOutput(
 				sqrtRatioCurrentX96,
 				liquidity,
 				absAmountRemaining,
-				zeroForOne)
+				zeroForOne,
+				strict)
+			if err != nil {
+				return
+			}
 		}
 	}
 
@@ -305,19 +534,27 @@ func ComputeSwapStep(
 	// get the input/output amounts
 	if zeroForOne {
 		if !(max && exactIn) {
-			amountIn = GetAmount0DeltaRoundingUp(sqrtRatioNextX96, sqrtRatioCurrentX96, liquidity, true)
+			if amountIn, err = TryGetAmount0DeltaRoundingUp(sqrtRatioNextX96, sqrtRatioCurrentX96, liquidity, true, strict); err != nil {
+				return
+			}
 		}
 
 		if !(max && !exactIn) {
-			amountOut = GetAmount1DeltaRoundingUp(sqrtRatioNextX96, sqrtRatioCurrentX96, liquidity, false)
+			if amountOut, err = TryGetAmount1DeltaRoundingUp(sqrtRatioNextX96, sqrtRatioCurrentX96, liquidity, false, strict); err != nil {
+				return
+			}
 		}
 	} else {
 		if !(max && exactIn) {
-			amountIn = GetAmount1DeltaRoundingUp(sqrtRatioCurrentX96, sqrtRatioNextX96, liquidity, true)
+			if amountIn, err = TryGetAmount1DeltaRoundingUp(sqrtRatioCurrentX96, sqrtRatioNextX96, liquidity, true, strict); err != nil {
+				return
+			}
 		}
 
 		if !(max && !exactIn) {
-			amountOut = GetAmount0DeltaRoundingUp(sqrtRatioCurrentX96, sqrtRatioNextX96, liquidity, false)
+			if amountOut, err = TryGetAmount0DeltaRoundingUp(sqrtRatioCurrentX96, sqrtRatioNextX96, liquidity, false, strict); err != nil {
+				return
+			}
 		}
 	}
 
@@ -329,7 +566,7 @@ func ComputeSwapStep(
 		feeAmount = big.NewInt(0)
 		feeAmount.Sub(amountRemaining, amountIn)
 	} else {
-		feeAmount = MulDivRoundingUp(amountIn, feePips, oneSubFeePips)
+		feeAmount, err = TryMulDivRoundingUp(amountIn, feePips, oneSubFeePips, strict)
 	}
 
 	return
@@ -1,8 +1,8 @@
 package uniswap_core
 
 import (
+	"errors"
 	"fmt"
-	"github.com/machinebox/graphql"
 	"math/big"
 	"testing"
 )
@@ -37,28 +37,252 @@ func TestComputeSwapStep(t *testing.T) {
 	}
 }
 
-func TestDoSwap(t *testing.T) {
-	zeroForOne := true
+func TestDoSwapErrors(t *testing.T) {
+	pool, ticks := newTestPool()
+	ticker := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+	amountSpecified := big.NewInt(1000)
 
-	client := graphql.NewClient("https://api.thegraph.com/subgraphs/name/uniswap/uniswap-v3")
-	poolId := "0x8ad599c3a0ff1de082011efddc58f1908eb6e6d8"
-	ticks, err := GetTicks(client, poolId)
+	locked := *pool
+	locked.SqrtPrice = BigInt{Val: big.NewInt(0)}
+
+	if _, err := DoSwap(true, amountSpecified, big.NewInt(0), ticker, locked); !errors.Is(err, ErrPoolLocked) {
+		t.Errorf("DoSwap() error = %v; want %v", err, ErrPoolLocked)
+	}
+
+	// the contract requires amountSpecified != 0 before anything else
+	for _, swap := range []func(bool, *big.Int, *big.Int, TickReader, PoolStateReader) (*SwapResult, error){DoSwap, DoSwapU256} {
+		if _, err := swap(true, big.NewInt(0), big.NewInt(0), ticker, locked); !errors.Is(err, ErrAmountSpecifiedZero) {
+			t.Errorf("DoSwap(0) error = %v; want %v", err, ErrAmountSpecifiedZero)
+		}
+	}
+
+	// the pool has no liquidity above the tick 1200, the swap stops at the price limit like on-chain
+	amountOut, _ := big.NewInt(0).SetString("-1000000000000000000000", 10)
+	res, err := DoSwap(false, amountOut, big.NewInt(0), ticker, pool)
 
 	if err != nil {
-		t.Errorf("TestDoSwap(...): %s", err)
+		t.Fatalf("DoSwap(): %s", err)
+	}
+
+	if !res.PriceLimitReached || res.Amount0.Cmp(amountOut) <= 0 {
+		t.Errorf("DoSwap() = %d, %t; want partial amount0 and reached price limit", res.Amount0, res.PriceLimitReached)
+	}
+}
+
+func TestDoSwapStrict(t *testing.T) {
+	pool, ticks := newTestPool()
+	ticker := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+	overMaxInt256 := big.NewInt(0).Add(MAX_INT_256, ONE_UINT_256)
+
+	if _, err := DoSwap(true, overMaxInt256, big.NewInt(0), ticker, pool); err != nil {
+		t.Errorf("DoSwap(%d): %s", overMaxInt256, err)
 	}
 
-	pool, err := GetPool(client, poolId)
+	if _, err := DoSwapStrict(true, overMaxInt256, big.NewInt(0), ticker, pool); !errors.Is(err, ErrOverflow) {
+		t.Errorf("DoSwapStrict(%d) error = %v; want %v", overMaxInt256, err, ErrOverflow)
+	}
+
+	// the results in range are the same as in the default mode
+	want, err := DoSwapU256(true, big.NewInt(1e18), big.NewInt(0), ticker, pool)
+	if err != nil {
+		t.Fatalf("DoSwapU256(): %s", err)
+	}
 
+	res, err := DoSwapStrict(true, big.NewInt(1e18), big.NewInt(0), ticker, pool)
 	if err != nil {
-		t.Errorf("TestDoSwap(...): %s", err)
+		t.Fatalf("DoSwapStrict(): %s", err)
+	}
+
+	if !swapResultsEqual(res, want) {
+		t.Errorf("DoSwapStrict() = %+v; want %+v", res, want)
 	}
+}
+
+func TestDoSwapPriceLimit(t *testing.T) {
+	pool, ticks := newTestPool()
+	ticker := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+	amountSpecified := big.NewInt(1000)
+	sqrtPriceX96 := pool.SqrtPrice.Val
 
-	amountSpecified := big.NewInt(-100000000000000)
+	below := GetSqrtRatioAtTick(big.NewInt(-10))
+	above := GetSqrtRatioAtTick(big.NewInt(10))
+
+	invalid := []struct {
+		zeroForOne        bool
+		sqrtPriceLimitX96 *big.Int
+	}{
+		{true, above},
+		{true, sqrtPriceX96},
+		{true, MIN_SQRT_RATIO},
+		{false, below},
+		{false, sqrtPriceX96},
+		{false, MAX_SQRT_RATIO},
+	}
+
+	for _, c := range invalid {
+		if _, err := DoSwap(c.zeroForOne, amountSpecified, c.sqrtPriceLimitX96, ticker, pool); !errors.Is(err, ErrPriceLimitInvalid) {
+			t.Errorf("DoSwap(%t, limit %d) error = %v; want %v", c.zeroForOne, c.sqrtPriceLimitX96, err, ErrPriceLimitInvalid)
+		}
+	}
+
+	// the limit is not changed by the swap and may be reused
 	sqrtPriceLimitX96 := big.NewInt(0)
 
-	ticker := NewTickStorage(ticks, pool.FeerTierToTickSpacing())
+	for _, zeroForOne := range []bool{true, false} {
+		if _, err := DoSwap(zeroForOne, amountSpecified, sqrtPriceLimitX96, ticker, pool); err != nil {
+			t.Fatalf("DoSwap(): %s", err)
+		}
+
+		if sqrtPriceLimitX96.Sign() != 0 {
+			t.Errorf("DoSwap() changed sqrtPriceLimitX96 to %d", sqrtPriceLimitX96)
+		}
+	}
+
+	res, err := DoSwap(true, amountSpecified, below, ticker, pool)
+
+	if err != nil {
+		t.Fatalf("DoSwap(): %s", err)
+	}
+
+	if res.Amount0.Cmp(amountSpecified) != 0 || res.PriceLimitReached {
+		t.Errorf("DoSwap() = %d, %t; want %d, false", res.Amount0, res.PriceLimitReached, amountSpecified)
+	}
+}
+
+func FuzzComputeSwapStep(f *testing.F) {
+	f.Add([]byte{}, []byte{0xff, 0xff}, uint64(0), uint64(10000), []byte{0x01, 0x2c}, true, uint32(3000))
+	f.Add([]byte{0xff, 0xff}, []byte{}, uint64(1), uint64(0), []byte{0x01, 0x2c}, false, uint32(500))
+	f.Add(big.NewInt(0).Sub(MAX_SQRT_RATIO, MIN_SQRT_RATIO).Bytes(), []byte{},
+		uint64(0xffffffffffffffff), uint64(0xffffffffffffffff), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, true, uint32(10000))
+
+	// the amounts which do not fit into the Solidity types revert like on-chain
+	f.Fuzz(func(t *testing.T, current []byte, target []byte, liquidityHi uint64, liquidityLo uint64,
+		amount []byte, exactIn bool, feePips uint32) {
+		sqrtRatioCurrentX96 := fuzzSqrtPrice(current)
+		sqrtRatioTargetX96 := fuzzSqrtPrice(target)
 
-	amount0, amount1, fee := DoSwap(zeroForOne, amountSpecified, sqrtPriceLimitX96, ticker, pool)
-	fmt.Println(amount0, amount1, fee)
+		liquidity := big.NewInt(0).SetUint64(liquidityHi)
+		liquidity.Lsh(liquidity, 64)
+		liquidity.Add(liquidity, big.NewInt(0).SetUint64(liquidityLo))
+
+		amountRemaining := big.NewInt(0).SetBytes(amount)
+		if !exactIn {
+			amountRemaining.Neg(amountRemaining)
+		}
+
+		fee := big.NewInt(int64(feePips % 1e6))
+
+		sqrtRatioNextX96, amountIn, amountOut, feeAmount, err := TryComputeSwapStep(
+			sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, amountRemaining, fee, true)
+		if err != nil {
+			return
+		}
+
+		args := fmt.Sprintf("%d, %d, %d, %d, %d", sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, amountRemaining, fee)
+		absAmountRemaining := big.NewInt(0).Abs(amountRemaining)
+
+		if exactIn {
+			if spent := big.NewInt(0).Add(amountIn, feeAmount); spent.Cmp(absAmountRemaining) > 0 {
+				t.Errorf("ComputeSwapStep(%s) amountIn + feeAmount = %d; want <= %d", args, spent, absAmountRemaining)
+			}
+		} else if amountOut.Cmp(absAmountRemaining) > 0 {
+			t.Errorf("ComputeSwapStep(%s) amountOut = %d; want <= %d", args, amountOut, absAmountRemaining)
+		}
+
+		if sqrtRatioCurrentX96.Cmp(sqrtRatioTargetX96) == 0 {
+			if sqrtRatioNextX96.Cmp(sqrtRatioTargetX96) != 0 || amountIn.Sign() != 0 || amountOut.Sign() != 0 || feeAmount.Sign() != 0 {
+				t.Errorf("ComputeSwapStep(%s) = %d, %d, %d, %d; want target and zero amounts",
+					args, sqrtRatioNextX96, amountIn, amountOut, feeAmount)
+			}
+		}
+
+		// the whole amount is consumed if the step stops before the target
+		if sqrtRatioNextX96.Cmp(sqrtRatioTargetX96) != 0 {
+			if exactIn {
+				if spent := big.NewInt(0).Add(amountIn, feeAmount); spent.Cmp(absAmountRemaining) != 0 {
+					t.Errorf("ComputeSwapStep(%s) amountIn + feeAmount = %d; want %d", args, spent, absAmountRemaining)
+				}
+			} else if amountOut.Cmp(absAmountRemaining) != 0 {
+				t.Errorf("ComputeSwapStep(%s) amountOut = %d; want %d", args, amountOut, absAmountRemaining)
+			}
+		}
+
+		// the next price is between the current and the target prices
+		lower, upper := sqrtRatioCurrentX96, sqrtRatioTargetX96
+		if lower.Cmp(upper) > 0 {
+			lower, upper = upper, lower
+		}
+
+		if sqrtRatioNextX96.Cmp(lower) < 0 || sqrtRatioNextX96.Cmp(upper) > 0 {
+			t.Errorf("ComputeSwapStep(%s) sqrtRatioNextX96 = %d; want in [%d, %d]", args, sqrtRatioNextX96, lower, upper)
+		}
+	})
+}
+
+func FuzzDoSwap(f *testing.F) {
+	f.Add(true, true, uint64(1000), uint64(1001))
+	f.Add(false, false, uint64(1e15), uint64(1e18))
+	f.Add(true, false, uint64(1e18), uint64(1e19))
+	f.Add(false, true, uint64(1), uint64(0xffffffffffffffff))
+
+	pool, ticks := newTestPool()
+	ticker := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+	sqrtPriceLimitX96 := big.NewInt(0)
+
+	f.Fuzz(func(t *testing.T, zeroForOne bool, exactIn bool, a uint64, b uint64) {
+		if a > b {
+			a, b = b, a
+		}
+
+		if a == 0 {
+			return
+		}
+
+		swap := func(amount uint64) (amountIn *big.Int, amountOut *big.Int, res *SwapResult) {
+			amountSpecified := big.NewInt(0).SetUint64(amount)
+			if !exactIn {
+				amountSpecified.Neg(amountSpecified)
+			}
+
+			res, err := DoSwap(zeroForOne, amountSpecified, sqrtPriceLimitX96, ticker, pool)
+			if err != nil {
+				t.Fatalf("DoSwap(%t, %d): %s", zeroForOne, amountSpecified, err)
+			}
+
+			amountIn, amountOut = res.Amount0, big.NewInt(0).Neg(res.Amount1)
+			if !zeroForOne {
+				amountIn, amountOut = res.Amount1, big.NewInt(0).Neg(res.Amount0)
+			}
+
+			limit := big.NewInt(0).SetUint64(amount)
+			if exactIn && amountIn.Cmp(limit) > 0 || !exactIn && amountOut.Cmp(limit) > 0 {
+				t.Errorf("DoSwap(%t, %d) = %d, %d; want the specified amount not exceeded", zeroForOne, amountSpecified, amountIn, amountOut)
+			}
+
+			if amountIn.Sign() < 0 || amountOut.Sign() < 0 {
+				t.Errorf("DoSwap(%t, %d) = %d, %d; want the input paid and the output received", zeroForOne, amountSpecified, amountIn, amountOut)
+			}
+
+			return
+		}
+
+		inA, outA, resA := swap(a)
+		inB, outB, resB := swap(b)
+
+		// the bigger swap pays more, receives more and moves the price further in the swap direction
+		if inA.Cmp(inB) > 0 || outA.Cmp(outB) > 0 {
+			t.Errorf("DoSwap(%t, %t, %d) = %d, %d and DoSwap(%d) = %d, %d; want monotonic amounts",
+				zeroForOne, exactIn, a, inA, outA, b, inB, outB)
+		}
+
+		prices := []*big.Int{pool.SqrtPrice.Val, resA.SqrtPriceX96, resB.SqrtPriceX96}
+		if !zeroForOne {
+			prices[0], prices[2] = prices[2], prices[0]
+		}
+
+		if prices[0].Cmp(prices[1]) < 0 || prices[1].Cmp(prices[2]) < 0 {
+			t.Errorf("DoSwap(%t, %t, %d) price = %d and DoSwap(%d) price = %d; want monotonic prices from %d",
+				zeroForOne, exactIn, a, resA.SqrtPriceX96, b, resB.SqrtPriceX96, pool.SqrtPrice.Val)
+		}
+	})
 }
@@ -0,0 +1,315 @@
+package uniswap_core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/holiman/uint256"
+)
+
+// the result of a single fixed-width swap step
+type SwapStepU256 struct {
+	// the price after swapping the amount in/out, not to exceed the price target
+	SqrtRatioNextX96 uint256.Int
+	// the amount to be swapped in, of either token0 or token1, based on the direction of the swap
+	AmountIn uint256.Int
+	// the amount to be received, of either token0 or token1, based on the direction of the swap
+	AmountOut uint256.Int
+	// the amount of input that will be taken as a fee
+	FeeAmount uint256.Int
+}
+
+const feePipsDenominator = 1e6
+
+// Fixed-width TryComputeSwapStep, the result is stored into step without allocations
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/SwapMath.sol
+// amountRemaining	uint256.Int	int256 in two's complement, positive for exact input, negative for exact output
+// feePips	uint32	uint24 fee in hundredths of a bip
+func ComputeSwapStepU256(
+	sqrtRatioCurrentX96 *uint256.Int,
+	sqrtRatioTargetX96 *uint256.Int,
+	liquidity *uint256.Int,
+	amountRemaining *uint256.Int,
+	feePips uint32,
+	step *SwapStepU256) (err error) {
+
+	zeroForOne := !sqrtRatioCurrentX96.Lt(sqrtRatioTargetX96)
+	exactIn := amountRemaining.Sign() >= 0
+
+	var absAmountRemaining, oneSubFeePips, feeDenominator, amountRemainingLessFee uint256.Int
+	absAmountRemaining.Abs(amountRemaining)
+	feeDenominator.SetUint64(feePipsDenominator)
+	oneSubFeePips.SetUint64(feePipsDenominator - uint64(feePips))
+
+	next := &step.SqrtRatioNextX96
+
+	if exactIn {
+		if MulDivU256(&amountRemainingLessFee, amountRemaining, &oneSubFeePips, &feeDenominator) {
+			return fmt.Errorf("%w: swap: amount remaining less fee", ErrOverflow)
+		}
+
+		if zeroForOne {
+			_, err = GetAmount0DeltaRoundingUpU256(&step.AmountIn, sqrtRatioTargetX96, sqrtRatioCurrentX96, liquidity, true)
+		} else {
+			_, err = GetAmount1DeltaRoundingUpU256(&step.AmountIn, sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, true)
+		}
+		if err != nil {
+			return
+		}
+
+		if !amountRemainingLessFee.Lt(&step.AmountIn) {
+			next.Set(sqrtRatioTargetX96)
+		} else if _, err = GetNextSqrtPriceFromInputU256(
+			next, sqrtRatioCurrentX96, liquidity, &amountRemainingLessFee, zeroForOne); err != nil {
+			return
+		}
+	} else {
+		if zeroForOne {
+			_, err = GetAmount1DeltaRoundingUpU256(&step.AmountOut, sqrtRatioTargetX96, sqrtRatioCurrentX96, liquidity, false)
+		} else {
+			_, err = GetAmount0DeltaRoundingUpU256(&step.AmountOut, sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, false)
+		}
+		if err != nil {
+			return
+		}
+
+		if !absAmountRemaining.Lt(&step.AmountOut) {
+			next.Set(sqrtRatioTargetX96)
+		} else if _, err = GetNextSqrtPriceFromOutputU256(
+			next, sqrtRatioCurrentX96, liquidity, &absAmountRemaining, zeroForOne); err != nil {
+			return
+		}
+	}
+
+	max := sqrtRatioTargetX96.Eq(next)
+
+	// get the input/output amounts
+	if zeroForOne {
+		if !(max && exactIn) {
+			if _, err = GetAmount0DeltaRoundingUpU256(&step.AmountIn, next, sqrtRatioCurrentX96, liquidity, true); err != nil {
+				return
+			}
+		}
+
+		if !(max && !exactIn) {
+			if _, err = GetAmount1DeltaRoundingUpU256(&step.AmountOut, next, sqrtRatioCurrentX96, liquidity, false); err != nil {
+				return
+			}
+		}
+	} else {
+		if !(max && exactIn) {
+			if _, err = GetAmount1DeltaRoundingUpU256(&step.AmountIn, sqrtRatioCurrentX96, next, liquidity, true); err != nil {
+				return
+			}
+		}
+
+		if !(max && !exactIn) {
+			if _, err = GetAmount0DeltaRoundingUpU256(&step.AmountOut, sqrtRatioCurrentX96, next, liquidity, false); err != nil {
+				return
+			}
+		}
+	}
+
+	if !exactIn && step.AmountOut.Gt(&absAmountRemaining) {
+		step.AmountOut.Set(&absAmountRemaining)
+	}
+
+	if exactIn && !max {
+		step.FeeAmount.Sub(amountRemaining, &step.AmountIn)
+	} else if MulDivRoundingUpU256(&step.FeeAmount, &step.AmountIn, feeDenominator.SetUint64(uint64(feePips)), &oneSubFeePips) {
+		err = fmt.Errorf("%w: swap: fee amount", ErrOverflow)
+	}
+
+	return
+}
+
+// DoSwapU256 is DoSwap which runs the swap loop on fixed-width integers.
+// The arguments and the result are the same as of DoSwap, the ticks and the liquidity nets are converted on the fly,
+// so a step which does not cross an initialized tick does not allocate.
+// The bit widths are always enforced like in DoSwapStrict: the amounts out of int256 and the overflows
+// of the fee math result in ErrOverflow, the liquidity of the crossed ticks out of uint128 results
+// in ErrInsufficientLiquidity ('LS') or ErrOverflow ('LA'), the protocol fee wraps like the uint128 of the contract
+func DoSwapU256(zeroForOne bool,
+	amountSpecified *big.Int,
+	sqrtPriceLimitX96 *big.Int,
+	ticker TickReader,
+	slotReader PoolStateReader) (*SwapResult, error) {
+	slot0, err := slotReader.CurrentState()
+	if err != nil {
+		return nil, err
+	}
+	return doSwapU256(zeroForOne, amountSpecified, sqrtPriceLimitX96, ticker, slot0)
+}
+
+func doSwapU256(zeroForOne bool,
+	amountSpecified *big.Int,
+	sqrtPriceLimitX96 *big.Int,
+	ticker TickReader,
+	slot0 *Slot0) (*SwapResult, error) {
+
+	if amountSpecified.Sign() == 0 {
+		return nil, fmt.Errorf("%w: nothing to swap", ErrAmountSpecifiedZero)
+	}
+
+	if slot0.SqrtPriceX96.Cmp(ZERO_UINT_256) == 0 {
+		return nil, fmt.Errorf("%w: sqrtPriceX96 is not initialized", ErrPoolLocked)
+	}
+
+	sqrtPriceLimitX96, err := checkSqrtPriceLimitX96(zeroForOne, sqrtPriceLimitX96, slot0)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit, amountSpecifiedRemaining, amountCalculated, sqrtPriceX96, liquidity, feeGrowthGlobalX128,
+		feeGrowthGlobalX128Start, protocolFee, feeTotal, feeProtocol, sqrtPriceStartX96, sqrtPriceNextX96,
+		liquidityNet, delta uint256.Int
+
+	if u256FromBigSigned(&amountSpecifiedRemaining, amountSpecified) {
+		return nil, fmt.Errorf("%w: amountSpecified %d is out of int256", ErrOverflow, amountSpecified)
+	}
+
+	if slot0.Fee.Sign() < 0 || !slot0.Fee.IsUint64() || slot0.Fee.Uint64() >= feePipsDenominator {
+		return nil, fmt.Errorf("%w: fee %d is out of [0, %d)", ErrOverflow, slot0.Fee, uint64(feePipsDenominator))
+	}
+	feePips := uint32(slot0.Fee.Uint64())
+
+	cache := NewSwapCache(zeroForOne, slot0)
+	exactInput := amountSpecified.Sign() > 0
+
+	limit.SetFromBig(sqrtPriceLimitX96)
+	sqrtPriceX96.SetFromBig(slot0.SqrtPriceX96)
+	liquidity.SetFromBig(cache.liquidityStart)
+	feeProtocol.SetFromBig(cache.feeProtocol)
+
+	if zeroForOne {
+		feeGrowthGlobalX128Start.SetFromBig(slot0.FeeGrowthGlobal0X128)
+	} else {
+		feeGrowthGlobalX128Start.SetFromBig(slot0.FeeGrowthGlobal1X128)
+	}
+	feeGrowthGlobalX128.Set(&feeGrowthGlobalX128Start)
+
+	tick := int32(slot0.TickCurrent.Int64())
+	tickBig := big.NewInt(int64(tick))
+	var ticksCrossed []CrossedTick
+	var step SwapStepU256
+
+	for !amountSpecifiedRemaining.IsZero() && !sqrtPriceX96.Eq(&limit) {
+		sqrtPriceStartX96.Set(&sqrtPriceX96)
+
+		tickNextBig, initialized := ticker.NextInitializedTick(tickBig.SetInt64(int64(tick)), zeroForOne)
+		tickNext := minTickInt32
+		if tickNextBig.Cmp(MIN_TICK) >= 0 {
+			tickNext = maxTickInt32
+			if tickNextBig.Cmp(MAX_TICK) <= 0 {
+				tickNext = int32(tickNextBig.Int64())
+			}
+		}
+
+		if _, err := GetSqrtRatioAtTickU256(&sqrtPriceNextX96, tickNext); err != nil {
+			return nil, err
+		}
+
+		target := &sqrtPriceNextX96
+		if zeroForOne == target.Lt(&limit) {
+			target = &limit
+		}
+
+		if err := ComputeSwapStepU256(&sqrtPriceX96, target, &liquidity, &amountSpecifiedRemaining, feePips, &step); err != nil {
+			return nil, err
+		}
+		sqrtPriceX96.Set(&step.SqrtRatioNextX96)
+
+		feeTotal.Add(&feeTotal, &step.FeeAmount)
+
+		// the checked arithmetic of the contract, the input includes the fee
+		_, overflow := delta.AddOverflow(&step.AmountIn, &step.FeeAmount)
+		if exactInput {
+			overflow = overflow || addInt256U256(&amountSpecifiedRemaining, &amountSpecifiedRemaining, &delta, true)
+			overflow = overflow || addInt256U256(&amountCalculated, &amountCalculated, &step.AmountOut, true)
+		} else {
+			overflow = overflow || addInt256U256(&amountSpecifiedRemaining, &amountSpecifiedRemaining, &step.AmountOut, false)
+			overflow = overflow || addInt256U256(&amountCalculated, &amountCalculated, &delta, false)
+		}
+		if overflow {
+			return nil, fmt.Errorf("%w: swap: amounts %d in, %d out do not fit int256",
+				ErrOverflow, step.AmountIn.ToBig(), step.AmountOut.ToBig())
+		}
+
+		if !feeProtocol.IsZero() {
+			delta.Div(&step.FeeAmount, &feeProtocol)
+			step.FeeAmount.Sub(&step.FeeAmount, &delta)
+			// the unchecked uint128 arithmetic of the contract
+			protocolFee.Add(&protocolFee, &delta)
+			protocolFee.And(&protocolFee, &maxUint128U256)
+		}
+
+		if !liquidity.IsZero() {
+			if MulDivU256(&delta, &step.FeeAmount, &q128U256, &liquidity) {
+				return nil, fmt.Errorf("%w: swap: fee growth of fee %d, liquidity %d", ErrOverflow, step.FeeAmount.ToBig(), liquidity.ToBig())
+			}
+			feeGrowthGlobalX128.Add(&feeGrowthGlobalX128, &delta)
+		}
+
+		if sqrtPriceX96.Eq(&sqrtPriceNextX96) {
+			if initialized {
+				liquidityNetBig := ticker.GetLiquidityNet(tickNextBig)
+
+				ticksCrossed = append(ticksCrossed, CrossedTick{
+					Tick:                big.NewInt(int64(tickNext)),
+					LiquidityNet:        big.NewInt(0).Set(liquidityNetBig),
+					FeeGrowthGlobalX128: feeGrowthGlobalX128.ToBig()})
+
+				if u256FromBigSigned(&liquidityNet, liquidityNetBig) {
+					return nil, fmt.Errorf("%w: swap: liquidityNet %d of tick %d", ErrOverflow, liquidityNetBig, tickNext)
+				}
+				if zeroForOne {
+					liquidityNet.Neg(&liquidityNet)
+				}
+
+				// LiquidityMath.addDelta, 'LS' and 'LA'
+				negative := liquidityNet.Sign() < 0
+				liquidity.Add(&liquidity, &liquidityNet)
+				if negative && liquidity.Sign() < 0 {
+					return nil, fmt.Errorf("%w: swap: liquidity %d less than %d", ErrInsufficientLiquidity,
+						delta.Sub(&liquidity, &liquidityNet).ToBig(), delta.Neg(&liquidityNet).ToBig())
+				}
+				if liquidity.Gt(&maxUint128U256) {
+					return nil, fmt.Errorf("%w: swap: liquidity %d exceeds uint128", ErrOverflow, liquidity.ToBig())
+				}
+			}
+
+			if zeroForOne {
+				tick = tickNext - 1
+			} else {
+				tick = tickNext
+			}
+		} else if !sqrtPriceX96.Eq(&sqrtPriceStartX96) {
+			// recompute unless we're on a lower tick boundary (i.e. already transitioned ticks), and haven't moved
+			if tick, err = GetTickAtSqrtRatioU256(&sqrtPriceX96); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := &SwapResult{
+		FeeTotal:                 feeTotal.ToBig(),
+		ProtocolFee:              protocolFee.ToBig(),
+		SqrtPriceX96:             sqrtPriceX96.ToBig(),
+		Tick:                     big.NewInt(int64(tick)),
+		Liquidity:                u256ToBigSigned(&liquidity),
+		FeeGrowthGlobalX128Delta: delta.Sub(&feeGrowthGlobalX128, &feeGrowthGlobalX128Start).ToBig(),
+		TicksCrossed:             ticksCrossed,
+		PriceLimitReached:        sqrtPriceX96.Eq(&limit)}
+
+	delta.SetFromBig(amountSpecified)
+	amountSpecifiedRemaining.Sub(&delta, &amountSpecifiedRemaining)
+	if zeroForOne == exactInput {
+		result.Amount0 = u256ToBigSigned(&amountSpecifiedRemaining)
+		result.Amount1 = u256ToBigSigned(&amountCalculated)
+	} else {
+		result.Amount0 = u256ToBigSigned(&amountCalculated)
+		result.Amount1 = u256ToBigSigned(&amountSpecifiedRemaining)
+	}
+
+	return result, nil
+}
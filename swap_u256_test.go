@@ -0,0 +1,259 @@
+package uniswap_core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+)
+
+func TestComputeSwapStepU256(t *testing.T) {
+	var current, target, liquidity, amountRemaining uint256.Int
+	var step SwapStepU256
+
+	cases := []struct {
+		tickCur, tickNext int64
+		liquidity         string
+		amountRemaining   string
+		feePips           uint32
+	}{
+		{0, 60, "10000", "300", 10000},
+		{-60, 0, "10000", "300", 10000},
+		{100, 200, "100000000", "3000", 3000},
+		{0, 600, "3000000000000000000", "1000000000000000", 3000},
+		{0, -600, "3000000000000000000", "-1000000000000000", 500},
+		{0, -600, "3000000000000000000", "-1000000000000000000000", 500},
+		{600, 0, "1000000000000000000", "1000000000000000000000", 10000},
+		{-887272, 887272, "340282366920938463463374607431768211455", "1000", 100},
+	}
+
+	for _, c := range cases {
+		liq, _ := big.NewInt(0).SetString(c.liquidity, 10)
+		amount, _ := big.NewInt(0).SetString(c.amountRemaining, 10)
+		sqrtPriceCurrentX96 := GetSqrtRatioAtTick(big.NewInt(c.tickCur))
+		sqrtPriceTargetX96 := GetSqrtRatioAtTick(big.NewInt(c.tickNext))
+
+		next, amountIn, amountOut, feeAmount := ComputeSwapStep(
+			sqrtPriceCurrentX96, sqrtPriceTargetX96, liq, amount, big.NewInt(int64(c.feePips)))
+
+		current.SetFromBig(sqrtPriceCurrentX96)
+		target.SetFromBig(sqrtPriceTargetX96)
+		liquidity.SetFromBig(liq)
+		amountRemaining.SetFromBig(amount)
+
+		if err := ComputeSwapStepU256(&current, &target, &liquidity, &amountRemaining, c.feePips, &step); err != nil {
+			t.Fatalf("ComputeSwapStepU256(%v): %s", c, err)
+		}
+
+		if step.SqrtRatioNextX96.ToBig().Cmp(next) != 0 ||
+			step.AmountIn.ToBig().Cmp(amountIn) != 0 ||
+			step.AmountOut.ToBig().Cmp(amountOut) != 0 ||
+			step.FeeAmount.ToBig().Cmp(feeAmount) != 0 {
+			t.Errorf("ComputeSwapStepU256(%v) = %d, %d, %d, %d; want %d, %d, %d, %d", c,
+				&step.SqrtRatioNextX96, &step.AmountIn, &step.AmountOut, &step.FeeAmount,
+				next, amountIn, amountOut, feeAmount)
+		}
+	}
+}
+
+func swapResultsEqual(a *SwapResult, b *SwapResult) bool {
+	if a.Amount0.Cmp(b.Amount0) != 0 ||
+		a.Amount1.Cmp(b.Amount1) != 0 ||
+		a.FeeTotal.Cmp(b.FeeTotal) != 0 ||
+		a.ProtocolFee.Cmp(b.ProtocolFee) != 0 ||
+		a.SqrtPriceX96.Cmp(b.SqrtPriceX96) != 0 ||
+		a.Tick.Cmp(b.Tick) != 0 ||
+		a.Liquidity.Cmp(b.Liquidity) != 0 ||
+		a.FeeGrowthGlobalX128Delta.Cmp(b.FeeGrowthGlobalX128Delta) != 0 ||
+		a.PriceLimitReached != b.PriceLimitReached ||
+		len(a.TicksCrossed) != len(b.TicksCrossed) {
+		return false
+	}
+
+	for i := range a.TicksCrossed {
+		if a.TicksCrossed[i].Tick.Cmp(b.TicksCrossed[i].Tick) != 0 ||
+			a.TicksCrossed[i].LiquidityNet.Cmp(b.TicksCrossed[i].LiquidityNet) != 0 ||
+			a.TicksCrossed[i].FeeGrowthGlobalX128.Cmp(b.TicksCrossed[i].FeeGrowthGlobalX128) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestDoSwapU256(t *testing.T) {
+	pool, ticks := newTestPool()
+	pool.FeeProtocol = BigInt{Val: big.NewInt(4 + 6<<4)}
+	pool.FeeGrowthGlobal0X128 = BigInt{Val: big.NewInt(0).Sub(MAX_UINT_256, big.NewInt(1000))}
+	ticker := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+
+	amounts := []string{
+		"1000", "-1000", "1000000000000000000", "-1000000000000000000",
+		"100000000000000000000", "-100000000000000000000",
+		// the bounds of int256 are accepted like by the pool
+		MAX_INT_256.String(), MIN_INT_256.String()}
+	limits := []*big.Int{big.NewInt(0), GetSqrtRatioAtTick(big.NewInt(-900)), GetSqrtRatioAtTick(big.NewInt(900))}
+
+	for _, zeroForOne := range []bool{true, false} {
+		for _, a := range amounts {
+			for _, limit := range limits {
+				amountSpecified, _ := big.NewInt(0).SetString(a, 10)
+
+				want, wantErr := DoSwap(zeroForOne, amountSpecified, limit, ticker, pool)
+				got, err := DoSwapU256(zeroForOne, amountSpecified, limit, ticker, pool)
+
+				if (wantErr == nil) != (err == nil) {
+					t.Errorf("DoSwapU256(%t, %s, %d) error = %v; want %v", zeroForOne, a, limit, err, wantErr)
+					continue
+				}
+
+				if err == nil && !swapResultsEqual(got, want) {
+					t.Errorf("DoSwapU256(%t, %s, %d) = %+v; want %+v", zeroForOne, a, limit, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDoSwapU256Errors(t *testing.T) {
+	// the pool liquidity does not add up with the liquidity net of the ticks
+	drained, ticks := newTestPool()
+	drained.Liquidity = BigInt{Val: big.NewInt(1000)}
+
+	full, _ := newTestPool()
+	full.Liquidity = BigInt{Val: big.NewInt(0).Sub(MAX_UINT_128, ONE_UINT_256)}
+
+	amountSpecified, _ := big.NewInt(0).SetString("10000000000000000000000000000000000000000", 10)
+
+	cases := []struct {
+		name       string
+		pool       *Pool
+		ticks      []Tick
+		zeroForOne bool
+		wantErr    error
+	}{
+		// 'LS': crossing -600 subtracts more than the pool has
+		{"LS", drained, ticks, true, ErrInsufficientLiquidity},
+		// 'LA': crossing 600 adds the liquidity net to the uint128 max
+		{"LA", full, []Tick{{
+			TickIdx:               BigInt{Val: big.NewInt(600)},
+			LiquidityGross:        BigInt{Val: big.NewInt(1e18)},
+			LiquidityNet:          BigInt{Val: big.NewInt(1e18)},
+			FeeGrowthOutside0X128: BigInt{Val: big.NewInt(0)},
+			FeeGrowthOutside1X128: BigInt{Val: big.NewInt(0)}}}, false, ErrOverflow},
+	}
+
+	for _, c := range cases {
+		ticker := NewTickStorage(c.ticks, c.pool.MustFeeTierToTickSpacing())
+
+		for _, swap := range []func(bool, *big.Int, *big.Int, TickReader, PoolStateReader) (*SwapResult, error){DoSwapStrict, DoSwapU256} {
+			if res, err := swap(c.zeroForOne, amountSpecified, big.NewInt(0), ticker, c.pool); !errors.Is(err, c.wantErr) {
+				t.Errorf("%s: DoSwap() = %+v, %v; want %v", c.name, res, err, c.wantErr)
+			}
+		}
+	}
+}
+
+func TestAddInt256U256(t *testing.T) {
+	var x, y, z uint256.Int
+	minInt256, _ := uint256.FromBig(MIN_INT_256)
+	maxInt256, _ := uint256.FromBig(MAX_INT_256)
+
+	cases := []struct {
+		x            *uint256.Int
+		y            *uint256.Int
+		sub          bool
+		want         *big.Int
+		wantOverflow bool
+	}{
+		{uint256.NewInt(1), uint256.NewInt(2), true, big.NewInt(-1), false},
+		{z.Neg(uint256.NewInt(1)), uint256.NewInt(2), false, big.NewInt(1), false},
+		{maxInt256, uint256.NewInt(1), false, nil, true},
+		{minInt256, uint256.NewInt(1), true, nil, true},
+		// SafeCast.toInt256 of the amount
+		{uint256.NewInt(0), minInt256, false, nil, true},
+	}
+
+	for _, c := range cases {
+		x.Set(c.x)
+		y.Set(c.y)
+
+		overflow := addInt256U256(&x, &x, &y, c.sub)
+		if overflow != c.wantOverflow || !overflow && u256ToBigSigned(&x).Cmp(c.want) != 0 {
+			t.Errorf("addInt256U256(%d, %d, %t) = %d, %t; want %d, %t",
+				u256ToBigSigned(c.x), c.y, c.sub, u256ToBigSigned(&x), overflow, c.want, c.wantOverflow)
+		}
+	}
+}
+
+func TestU256FromBigSigned(t *testing.T) {
+	cases := []struct {
+		x            *big.Int
+		wantOverflow bool
+	}{
+		{big.NewInt(-1), false},
+		{MIN_INT_256, false},
+		{MAX_INT_256, false},
+		{big.NewInt(0).Sub(MIN_INT_256, ONE_UINT_256), true},
+		{big.NewInt(0).Add(MAX_INT_256, ONE_UINT_256), true},
+	}
+
+	var z uint256.Int
+	for _, c := range cases {
+		overflow := u256FromBigSigned(&z, c.x)
+		if overflow != c.wantOverflow || !overflow && u256ToBigSigned(&z).Cmp(c.x) != 0 {
+			t.Errorf("u256FromBigSigned(%d) = %d, %t; want %d, %t", c.x, u256ToBigSigned(&z), overflow, c.x, c.wantOverflow)
+		}
+	}
+}
+
+func BenchmarkComputeSwapStep(b *testing.B) {
+	sqrtPriceCurrentX96 := GetSqrtRatioAtTick(big.NewInt(0))
+	sqrtPriceTargetX96 := GetSqrtRatioAtTick(big.NewInt(-600))
+	liquidity, _ := big.NewInt(0).SetString("3000000000000000000", 10)
+	amountRemaining := big.NewInt(1e15)
+	feePips := big.NewInt(3000)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ComputeSwapStep(sqrtPriceCurrentX96, sqrtPriceTargetX96, liquidity, amountRemaining, feePips)
+	}
+}
+
+func BenchmarkComputeSwapStepU256(b *testing.B) {
+	var current, target, liquidity, amountRemaining uint256.Int
+	var step SwapStepU256
+
+	current.SetFromBig(GetSqrtRatioAtTick(big.NewInt(0)))
+	target.SetFromBig(GetSqrtRatioAtTick(big.NewInt(-600)))
+	liquidity.SetUint64(3e18)
+	amountRemaining.SetUint64(1e15)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ComputeSwapStepU256(&current, &target, &liquidity, &amountRemaining, 3000, &step)
+	}
+}
+
+func benchmarkDoSwap(b *testing.B, swap func(bool, *big.Int, *big.Int, TickReader, PoolStateReader) (*SwapResult, error)) {
+	pool, ticks := newTestPool()
+	ticker := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+	amountSpecified, _ := big.NewInt(0).SetString("100000000000000000", 10)
+	sqrtPriceLimitX96 := big.NewInt(0)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := swap(true, amountSpecified, sqrtPriceLimitX96, ticker, pool); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDoSwap(b *testing.B) {
+	benchmarkDoSwap(b, DoSwap)
+}
+
+func BenchmarkDoSwapU256(b *testing.B) {
+	benchmarkDoSwap(b, DoSwapU256)
+}
@@ -0,0 +1,32 @@
+#!/bin/sh
+# Regenerates the pinned hardhat artifacts of Uniswap/v3-core read by evm_test.go, the artifacts and VERSION
+# are checked in, so the tests do not run this script. Requires network access, git, node 16 and yarn. The contracts are compiled from the given ref of v3-core, v1.0.0 by default,
+# with the solc version and the settings of its hardhat.config.ts and the dependencies of its yarn.lock.
+#
+# Usage: testdata/evm/build.sh [ref]
+set -eu
+
+REF=${1:-v1.0.0}
+OUT=$(cd "$(dirname "$0")" && pwd)
+WORK=$(mktemp -d)
+trap 'rm -rf "$WORK"' EXIT
+
+git clone --quiet https://github.com/Uniswap/v3-core "$WORK/v3-core"
+cd "$WORK/v3-core"
+git checkout --quiet "$REF"
+
+yarn install --frozen-lockfile
+yarn compile
+
+for artifact in \
+	UniswapV3Factory.sol/UniswapV3Factory \
+	UniswapV3Pool.sol/UniswapV3Pool \
+	test/TickMathTest.sol/TickMathTest \
+	test/SqrtPriceMathTest.sol/SqrtPriceMathTest \
+	test/SwapMathTest.sol/SwapMathTest \
+	test/TestERC20.sol/TestERC20 \
+	test/TestUniswapV3Callee.sol/TestUniswapV3Callee; do
+	cp "artifacts/contracts/$artifact.json" "$OUT/"
+done
+
+echo "v3-core $(git rev-parse HEAD)" > "$OUT/VERSION"
@@ -0,0 +1,181 @@
+package uniswap_core
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/holiman/uint256"
+)
+
+// TickBitmap is the packed map of initialized ticks of the pool, the bit of the compressed tick
+// (tick / tickSpacing) is stored in the 256-bit word at the position compressed >> 8, bit compressed % 256.
+// It reads the tick data from the storage and has to be flipped by the caller whenever a tick of the storage
+// is flipped, like the pool does after Tick.update
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/TickBitmap.sol
+type TickBitmap struct {
+	Ticks *TickStorage
+	words map[int16]uint256.Int
+}
+
+// NewTickBitmap returns the bitmap of the initialized ticks of the storage
+func NewTickBitmap(ticks *TickStorage) *TickBitmap {
+	b := &TickBitmap{
+		Ticks: ticks,
+		words: make(map[int16]uint256.Int)}
+
+	for key := range ticks.Ticks {
+		if tick := big.NewInt(key); ticks.IsInitialized(tick) {
+			b.flip(b.compress(key))
+		}
+	}
+
+	return b
+}
+
+// fork returns the copy of the bitmap reading the tick data from the given storage, e.g. the fork of b.Ticks
+func (b *TickBitmap) fork(ticks *TickStorage) *TickBitmap {
+	f := &TickBitmap{
+		Ticks: ticks,
+		words: make(map[int16]uint256.Int, len(b.words))}
+
+	for wordPos, word := range b.words {
+		f.words[wordPos] = word
+	}
+
+	return f
+}
+
+// Computes the position in the mapping where the initialized bit for a tick lives
+// tick	int64	The compressed tick for which to compute the position
+// return wordPos	The key in the mapping containing the word in which the bit is stored
+// return bitPos	The bit position in the word where the flag is stored
+func bitmapPosition(tick int64) (wordPos int16, bitPos uint) {
+	return int16(tick >> 8), uint(tick & 0xff)
+}
+
+// compress returns tick / tickSpacing rounded towards negative infinity
+func (b *TickBitmap) compress(tick int64) int64 {
+	tickSpacing := b.Ticks.TickSpacing.Int64()
+
+	compressed := tick / tickSpacing
+	if tick < 0 && tick%tickSpacing != 0 {
+		compressed--
+	}
+	return compressed
+}
+
+func (b *TickBitmap) flip(compressed int64) {
+	wordPos, bitPos := bitmapPosition(compressed)
+
+	var mask uint256.Int
+	mask.SetOne()
+	mask.Lsh(&mask, bitPos)
+
+	word := b.words[wordPos]
+	word.Xor(&word, &mask)
+
+	if word.IsZero() {
+		delete(b.words, wordPos)
+	} else {
+		b.words[wordPos] = word
+	}
+}
+
+// Flips the initialized state for a given tick from false to true, or vice versa
+// tick	big.Int	The tick to flip, ErrTickRangeInvalid if it is not a multiple of the tick spacing
+func (b *TickBitmap) Flip(tick *big.Int) error {
+	key := tick.Int64()
+
+	if key%b.Ticks.TickSpacing.Int64() != 0 {
+		return fmt.Errorf("%w: TickBitmap: tick %d must be a multiple of tick spacing %d", ErrTickRangeInvalid, tick, b.Ticks.TickSpacing)
+	}
+
+	b.flip(key / b.Ticks.TickSpacing.Int64())
+	return nil
+}
+
+// IsInitialized returns the bit of the tick
+func (b *TickBitmap) IsInitialized(tick *big.Int) bool {
+	key := tick.Int64()
+
+	if key%b.Ticks.TickSpacing.Int64() != 0 {
+		return false
+	}
+
+	wordPos, bitPos := bitmapPosition(key / b.Ticks.TickSpacing.Int64())
+	word := b.words[wordPos]
+	return word[bitPos/64]&(1<<(bitPos%64)) != 0
+}
+
+// Returns the next initialized tick contained in the same word (or adjacent word) as the tick that is either
+// to the left (less than or equal to) or right (greater than) of the given tick
+// tick	big.Int	The starting tick
+// lte	bool	Whether to search for the next initialized tick to the left (less than or equal to the starting tick)
+// return next	The next initialized or uninitialized tick up to 256 ticks away from the current tick
+// return initialized	Whether the next tick is initialized, as the function only searches within up to 256 ticks
+func (b *TickBitmap) NextInitializedTickWithinOneWord(tick *big.Int, lte bool) (next *big.Int, initialized bool) {
+	tickSpacing := b.Ticks.TickSpacing.Int64()
+	compressed := b.compress(tick.Int64())
+
+	var mask, masked uint256.Int
+
+	if lte {
+		wordPos, bitPos := bitmapPosition(compressed)
+		word := b.words[wordPos]
+
+		// all the 1s at or to the right of the current bitPos, wraps to all the 1s for bitPos 255
+		mask.SetOne()
+		mask.Lsh(&mask, bitPos+1)
+		mask.SubUint64(&mask, 1)
+		masked.And(&word, &mask)
+
+		// if there are no initialized ticks to the right of or at the current tick, return rightmost in the word
+		initialized = !masked.IsZero()
+		if initialized {
+			// overflow/underflow is possible, but prevented externally by limiting both tickSpacing and tick
+			msb := uint(masked.BitLen() - 1)
+			return big.NewInt((compressed - int64(bitPos-msb)) * tickSpacing), true
+		}
+		return big.NewInt((compressed - int64(bitPos)) * tickSpacing), false
+	}
+
+	// start from the word of the next tick, since the current tick state doesn't matter
+	wordPos, bitPos := bitmapPosition(compressed + 1)
+	word := b.words[wordPos]
+
+	// all the 1s at or to the left of the bitPos
+	mask.SetOne()
+	mask.Lsh(&mask, bitPos)
+	mask.SubUint64(&mask, 1)
+	mask.Not(&mask)
+	masked.And(&word, &mask)
+
+	// if there are no initialized ticks to the left of the current tick, return leftmost in the word
+	initialized = !masked.IsZero()
+	if initialized {
+		lsb := leastSignificantBit(&masked)
+		return big.NewInt((compressed + 1 + int64(lsb-bitPos)) * tickSpacing), true
+	}
+	return big.NewInt((compressed + 1 + int64(0xff-bitPos)) * tickSpacing), false
+}
+
+// leastSignificantBit returns the index of the lowest set bit of the non-zero x
+func leastSignificantBit(x *uint256.Int) uint {
+	for i, limb := range x {
+		if limb != 0 {
+			return uint(i*64 + bits.TrailingZeros64(limb))
+		}
+	}
+	return 256
+}
+
+// NextInitializedTick implements TickReader with the steps of the pool's swap loop,
+// i.e. the result is the next initialized tick or the boundary of the word
+func (b *TickBitmap) NextInitializedTick(tick *big.Int, zeroForOne bool) (*big.Int, bool) {
+	return b.NextInitializedTickWithinOneWord(tick, zeroForOne)
+}
+
+func (b *TickBitmap) GetLiquidityNet(tick *big.Int) *big.Int {
+	return b.Ticks.GetLiquidityNet(tick)
+}
@@ -0,0 +1,183 @@
+package uniswap_core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// newTestTickBitmap returns the bitmap with spacing 1 and the ticks of TickBitmap.spec.ts
+func newTestTickBitmap(t *testing.T, ticks ...int64) *TickBitmap {
+	t.Helper()
+
+	b := NewTickBitmap(NewTickStorage(nil, big.NewInt(1)))
+
+	for _, tick := range ticks {
+		if err := b.Flip(big.NewInt(tick)); err != nil {
+			t.Fatalf("TickBitmap.Flip(%d): %s", tick, err)
+		}
+	}
+
+	return b
+}
+
+func TestTickBitmapFlip(t *testing.T) {
+	b := newTestTickBitmap(t, -230)
+
+	for tick, want := range map[int64]bool{-230: true, -231: false, -229: false, -230 + 256: false, -230 - 256: false} {
+		if b.IsInitialized(big.NewInt(tick)) != want {
+			t.Errorf("TickBitmap.IsInitialized(%d) = %t; want %t", tick, !want, want)
+		}
+	}
+
+	// the word is removed when its last tick is flipped back
+	if err := b.Flip(big.NewInt(-230)); err != nil || b.IsInitialized(big.NewInt(-230)) || len(b.words) != 0 {
+		t.Errorf("TickBitmap.Flip(-230) = %v, %t, %d words; want nil, false, 0 words", err, b.IsInitialized(big.NewInt(-230)), len(b.words))
+	}
+
+	b = NewTickBitmap(NewTickStorage(nil, big.NewInt(60)))
+	if err := b.Flip(big.NewInt(61)); !errors.Is(err, ErrTickRangeInvalid) {
+		t.Errorf("TickBitmap.Flip(61) error = %v; want %v", err, ErrTickRangeInvalid)
+	}
+}
+
+func TestNextInitializedTickWithinOneWord(t *testing.T) {
+	initialized := []int64{-200, -55, -4, 70, 78, 84, 139, 240, 535}
+
+	cases := []struct {
+		flip        []int64
+		tick        int64
+		lte         bool
+		next        int64
+		initialized bool
+	}{
+		// lte = false
+		{nil, 78, false, 84, true},
+		{nil, -55, false, -4, true},
+		{nil, 77, false, 78, true},
+		{nil, -56, false, -55, true},
+		{nil, 255, false, 511, false},
+		{nil, -257, false, -200, true},
+		{[]int64{340}, 328, false, 340, true},
+		{nil, 508, false, 511, false},
+		{nil, 383, false, 511, false},
+		// lte = true
+		{nil, 78, true, 78, true},
+		{nil, 79, true, 78, true},
+		{nil, 258, true, 256, false},
+		{nil, 256, true, 256, false},
+		{nil, 72, true, 70, true},
+		{nil, -257, true, -512, false},
+		{nil, 1023, true, 768, false},
+		{nil, 900, true, 768, false},
+		{[]int64{329}, 456, true, 329, true},
+	}
+
+	for _, c := range cases {
+		b := newTestTickBitmap(t, append(initialized, c.flip...)...)
+
+		next, ok := b.NextInitializedTickWithinOneWord(big.NewInt(c.tick), c.lte)
+		if next.Int64() != c.next || ok != c.initialized {
+			t.Errorf("TickBitmap.NextInitializedTickWithinOneWord(%d, %t) = %d, %t; want %d, %t",
+				c.tick, c.lte, next, ok, c.next, c.initialized)
+		}
+	}
+
+	// the ticks are compressed by the spacing, the negative ones are rounded down
+	ticks := NewTickStorage([]Tick{*newTick(big.NewInt(-120)), *newTick(big.NewInt(180))}, big.NewInt(60))
+	ticks.Ticks[-120].LiquidityGross.Val.SetInt64(1)
+	ticks.Ticks[180].LiquidityGross.Val.SetInt64(1)
+	b := NewTickBitmap(ticks)
+
+	spaced := []struct {
+		tick        int64
+		lte         bool
+		next        int64
+		initialized bool
+	}{
+		{-61, true, -120, true},
+		{-120, true, -120, true},
+		{-121, true, -256 * 60, false},
+		{-121, false, -120, true},
+		{-120, false, -60, false},
+		{-60, false, 180, true},
+		{179, false, 180, true},
+		{180, false, 255 * 60, false},
+	}
+
+	for _, c := range spaced {
+		next, ok := b.NextInitializedTickWithinOneWord(big.NewInt(c.tick), c.lte)
+		if next.Int64() != c.next || ok != c.initialized {
+			t.Errorf("TickBitmap.NextInitializedTickWithinOneWord(%d, %t) = %d, %t; want %d, %t",
+				c.tick, c.lte, next, ok, c.next, c.initialized)
+		}
+	}
+}
+
+func TestDoSwapTickBitmap(t *testing.T) {
+	pool, ticks := newTestPool()
+	storage := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+	bitmap := NewTickBitmap(storage)
+
+	// the ticks of the test pool are in the adjacent words, so the steps are the same
+	for _, amount := range []int64{1000, 1e18, -1e18, 4e18} {
+		for _, zeroForOne := range []bool{true, false} {
+			want, err := DoSwap(zeroForOne, big.NewInt(amount), big.NewInt(0), storage, pool)
+			if err != nil {
+				t.Fatalf("DoSwap(%t, %d): %s", zeroForOne, amount, err)
+			}
+
+			res, err := DoSwap(zeroForOne, big.NewInt(amount), big.NewInt(0), bitmap, pool)
+			if err != nil {
+				t.Fatalf("DoSwap(%t, %d): %s", zeroForOne, amount, err)
+			}
+
+			if !swapResultsEqual(res, want) {
+				t.Errorf("DoSwap(%t, %d) with TickBitmap = %+v; want %+v", zeroForOne, amount, res, want)
+			}
+		}
+	}
+
+	// the swap down from the initialized tick crosses it first
+	pool.Tick = BigInt{Val: big.NewInt(-600)}
+	pool.SqrtPrice = BigInt{Val: GetSqrtRatioAtTick(pool.Tick.Val)}
+
+	for _, ticker := range []TickReader{storage, bitmap} {
+		res, err := DoSwap(true, big.NewInt(1e15), big.NewInt(0), ticker, pool)
+		if err != nil {
+			t.Fatalf("DoSwap(): %s", err)
+		}
+
+		if len(res.TicksCrossed) != 1 || res.TicksCrossed[0].Tick.Int64() != -600 || res.Liquidity.Cmp(big.NewInt(2e18)) != 0 {
+			t.Errorf("DoSwap() from tick -600 with %T = %+v; want tick -600 crossed", ticker, res)
+		}
+	}
+}
+
+func benchmarkNextInitializedTick(b *testing.B, newReader func([]Tick, *big.Int) TickReader) {
+	pool, ticks := loadPoolFixture(b, "usdc_usdt_100")
+	reader := newReader(ticks, pool.MustFeeTierToTickSpacing())
+
+	// the full range tick of the pool is the next one to the right of 40
+	tick := big.NewInt(40)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		next, initialized := tick, false
+		for !initialized {
+			next, initialized = reader.NextInitializedTick(next, false)
+		}
+	}
+}
+
+func BenchmarkNextInitializedTickTickStorage(b *testing.B) {
+	benchmarkNextInitializedTick(b, func(ticks []Tick, tickSpacing *big.Int) TickReader {
+		return NewTickStorage(ticks, tickSpacing)
+	})
+}
+
+func BenchmarkNextInitializedTickTickBitmap(b *testing.B) {
+	benchmarkNextInitializedTick(b, func(ticks []Tick, tickSpacing *big.Int) TickReader {
+		return NewTickBitmap(NewTickStorage(ticks, tickSpacing))
+	})
+}
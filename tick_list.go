@@ -0,0 +1,94 @@
+package uniswap_core
+
+import (
+	"math/big"
+	"sort"
+)
+
+// TickList is a read-only TickReader of the initialized ticks sorted by index, e.g. for quoting.
+// The lookups are binary searches which do not allocate, the returned values are shared by all the callers.
+// The steps are the same as the ones of TickStorage, see TickStorage.NextInitializedTick
+type TickList struct {
+	index        []int64
+	ticks        []*big.Int
+	liquidityNet []*big.Int
+}
+
+// NewTickList copies the initialized ticks which are multiples of the tick spacing,
+// the later tick with the same index replaces the earlier one like in NewTickStorage
+func NewTickList(ticks []Tick, tickSpacing *big.Int) *TickList {
+	spacing := tickSpacing.Int64()
+	initialized := make(map[int64]*Tick, len(ticks))
+
+	for i := range ticks {
+		key := ticks[i].TickIdx.Val.Int64()
+		if ticks[i].IsInitialized() && key%spacing == 0 {
+			initialized[key] = &ticks[i]
+		} else {
+			delete(initialized, key)
+		}
+	}
+
+	l := &TickList{
+		index:        make([]int64, 0, len(initialized)),
+		ticks:        make([]*big.Int, 0, len(initialized)),
+		liquidityNet: make([]*big.Int, 0, len(initialized))}
+
+	for key := range initialized {
+		l.index = append(l.index, key)
+	}
+	sort.Slice(l.index, func(i, j int) bool { return l.index[i] < l.index[j] })
+
+	for _, key := range l.index {
+		l.ticks = append(l.ticks, big.NewInt(key))
+		l.liquidityNet = append(l.liquidityNet, big.NewInt(0).Set(initialized[key].LiquidityNet.Val))
+	}
+
+	return l
+}
+
+func (l *TickList) Len() int {
+	return len(l.index)
+}
+
+// search returns the position of the first tick greater than the tick
+func (l *TickList) search(tick int64) int {
+	lo, hi := 0, len(l.index)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if l.index[mid] <= tick {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// NextInitializedTick returns the nearest initialized tick less than or equal to the tick (zeroForOne),
+// or greater than the tick, and the copy of MIN_TICK/MAX_TICK if there is no such tick
+func (l *TickList) NextInitializedTick(tick *big.Int, zeroForOne bool) (*big.Int, bool) {
+	i := l.search(tick.Int64())
+
+	if zeroForOne {
+		if i == 0 {
+			return new(big.Int).Set(MIN_TICK), false
+		}
+		return l.ticks[i-1], true
+	}
+
+	if i == len(l.ticks) {
+		return new(big.Int).Set(MAX_TICK), false
+	}
+	return l.ticks[i], true
+}
+
+// GetLiquidityNet returns nil if the tick is not initialized
+func (l *TickList) GetLiquidityNet(tick *big.Int) *big.Int {
+	key := tick.Int64()
+
+	if i := l.search(key); i > 0 && l.index[i-1] == key {
+		return l.liquidityNet[i-1]
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package uniswap_core
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestTickListNextInitializedTick(t *testing.T) {
+	for _, name := range poolFixtures {
+		pool, ticks := loadPoolFixture(t, name)
+		spacing := pool.MustFeeTierToTickSpacing()
+		storage := NewTickStorage(ticks, spacing)
+		list := NewTickList(ticks, spacing)
+
+		// every tick around the initialized ones and the bounds
+		starts := []int64{MIN_TICK.Int64(), MIN_TICK.Int64() + 1, 0, MAX_TICK.Int64() - 1}
+		for key := range storage.Ticks {
+			starts = append(starts, key-spacing.Int64()-1, key-1, key, key+1, key+spacing.Int64())
+		}
+
+		for _, start := range starts {
+			// the ticks of the swap loop are in [MIN_TICK, MAX_TICK)
+			if start < MIN_TICK.Int64() || start >= MAX_TICK.Int64() {
+				continue
+			}
+
+			for _, zeroForOne := range []bool{true, false} {
+				want, wantOk := storage.NextInitializedTick(big.NewInt(start), zeroForOne)
+				next, ok := list.NextInitializedTick(big.NewInt(start), zeroForOne)
+
+				if next.Cmp(want) != 0 || ok != wantOk {
+					t.Errorf("%s: TickList.NextInitializedTick(%d, %t) = %d, %t; want %d, %t",
+						name, start, zeroForOne, next, ok, want, wantOk)
+				}
+			}
+		}
+	}
+}
+
+func TestTickListBoundsCopy(t *testing.T) {
+	list := NewTickList(nil, big.NewInt(60))
+
+	// the bounds are returned as the copies, changing them does not change the package bounds
+	for _, zeroForOne := range []bool{true, false} {
+		next, ok := list.NextInitializedTick(big.NewInt(0), zeroForOne)
+		if ok || next == MIN_TICK || next == MAX_TICK {
+			t.Errorf("TickList.NextInitializedTick(0, %t) = %p, %t; want the copy of the bound, false", zeroForOne, next, ok)
+		}
+		next.Add(next, big.NewInt(1))
+	}
+
+	if MIN_TICK.Int64() != -887272 || MAX_TICK.Int64() != 887272 {
+		t.Errorf("MIN_TICK, MAX_TICK = %d, %d; want -887272, 887272", MIN_TICK, MAX_TICK)
+	}
+}
+
+func TestTickListGetLiquidityNet(t *testing.T) {
+	pool, ticks := newTestPool()
+	list := NewTickList(append(ticks, *newTick(big.NewInt(1200))), pool.MustFeeTierToTickSpacing())
+
+	if list.Len() != 3 {
+		t.Errorf("NewTickList().Len() = %d; want 3", list.Len())
+	}
+
+	for tick, want := range map[int64]*big.Int{-600: ticks[1].LiquidityNet.Val, 600: ticks[2].LiquidityNet.Val, 0: nil, 1200: nil} {
+		liquidityNet := list.GetLiquidityNet(big.NewInt(tick))
+
+		if (liquidityNet == nil) != (want == nil) || want != nil && liquidityNet.Cmp(want) != 0 {
+			t.Errorf("TickList.GetLiquidityNet(%d) = %d; want %d", tick, liquidityNet, want)
+		}
+	}
+}
+
+func TestDoSwapTickList(t *testing.T) {
+	for _, name := range poolFixtures {
+		pool, ticks := loadPoolFixture(t, name)
+		storage := NewTickStorage(ticks, pool.MustFeeTierToTickSpacing())
+		list := NewTickList(ticks, pool.MustFeeTierToTickSpacing())
+
+		// the swaps run twice to catch the changes of the shared values
+		for _, c := range append(swapGoldenCases(pool), swapGoldenCases(pool)...) {
+			want, err := DoSwap(c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, storage, pool)
+			if err != nil {
+				t.Fatalf("%s: DoSwap(%t, %d, %d): %s", name, c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, err)
+			}
+
+			res, err := DoSwap(c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, list, pool)
+			if err != nil {
+				t.Fatalf("%s: DoSwap(%t, %d, %d): %s", name, c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, err)
+			}
+
+			resU256, err := DoSwapU256(c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, list, pool)
+			if err != nil {
+				t.Fatalf("%s: DoSwapU256(%t, %d, %d): %s", name, c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, err)
+			}
+
+			if !swapResultsEqual(res, want) || !swapResultsEqual(resU256, want) {
+				t.Errorf("%s: DoSwap(%t, %d, %d) with TickList = %+v, %+v; want %+v",
+					name, c.ZeroForOne, c.AmountSpecified, c.SqrtPriceLimitX96, res, resU256, want)
+			}
+		}
+	}
+}
+
+func TestTickListAllocs(t *testing.T) {
+	pool, ticks := loadPoolFixture(t, "usdc_weth_500")
+	list := NewTickList(ticks, pool.MustFeeTierToTickSpacing())
+	tick := big.NewInt(0).Set(pool.Tick.Val)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, zeroForOne := range []bool{true, false} {
+			next, _ := list.NextInitializedTick(tick, zeroForOne)
+			list.GetLiquidityNet(next)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf("TickList allocations per call = %f; want 0", allocs)
+	}
+}
+
+func BenchmarkNextInitializedTickTickList(b *testing.B) {
+	benchmarkNextInitializedTick(b, func(ticks []Tick, tickSpacing *big.Int) TickReader {
+		return NewTickList(ticks, tickSpacing)
+	})
+}
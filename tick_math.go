@@ -18,6 +18,12 @@ var INIT0 *big.Int
 var INIT1 *big.Int
 var MAX_UINT_256 *big.Int
 var MAX_UINT_160 *big.Int
+var MAX_UINT_128 *big.Int
+var MAX_INT_256 *big.Int
+var MIN_INT_256 *big.Int
+var MAX_INT_128 *big.Int
+var MIN_INT_128 *big.Int
+var Q128 *big.Int
 var SQRT_10001 *big.Int
 var LOWER_ERR_BOUND *big.Int
 var UPPER_ERR_BOUND *big.Int
@@ -89,6 +95,12 @@ func init() {
 	INIT1, _ = hexutil.DecodeBig("0x100000000000000000000000000000000")
 	MAX_UINT_256 = GetMaxValue(256)
 	MAX_UINT_160 = GetMaxValue(160)
+	MAX_UINT_128 = GetMaxValue(128)
+	MAX_INT_256 = GetMaxValue(255)
+	MIN_INT_256 = new(big.Int).Not(MAX_INT_256)
+	MAX_INT_128 = GetMaxValue(127)
+	MIN_INT_128 = new(big.Int).Not(MAX_INT_128)
+	Q128 = new(big.Int).Lsh(common.Big1, 128)
 	SQRT_10001, _ = hexutil.DecodeBig("0x3627A301D71055774C85")
 	LOWER_ERR_BOUND, _ = hexutil.DecodeBig("0x28F6481AB7F045A5AF012A19D003AAA")
 	UPPER_ERR_BOUND, _ = hexutil.DecodeBig("0xDB2DF09E81959A81455E260799A0632F")
@@ -108,12 +120,22 @@ func GetMaxValue(bitsCount uint) *big.Int {
 // Source: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/TickMath.sol
 // tick int24
 // return int96 sqrtPriceX96
+// Panics with ErrTickOutOfRange if |tick| > MAX_TICK, see TryGetSqrtRatioAtTick
 func GetSqrtRatioAtTick(tick *big.Int) *big.Int {
+	ratio, err := TryGetSqrtRatioAtTick(tick)
+	if err != nil {
+		panic(err)
+	}
+	return ratio
+}
+
+// TryGetSqrtRatioAtTick is GetSqrtRatioAtTick which returns ErrTickOutOfRange instead of panicking
+func TryGetSqrtRatioAtTick(tick *big.Int) (*big.Int, error) {
 	absTick := big.NewInt(0)
 	absTick.Abs(tick)
 
 	if absTick.Cmp(MAX_TICK) > 0 {
-		panic(fmt.Sprintf("ticks: tick %d out of interval [%d, %d]", tick, MIN_TICK, MAX_TICK))
+		return nil, fmt.Errorf("%w: tick %d out of interval [%d, %d]", ErrTickOutOfRange, tick, MIN_TICK, MAX_TICK)
 	}
 
 	var ratio *big.Int = big.NewInt(0)
@@ -148,16 +170,27 @@ func GetSqrtRatioAtTick(tick *big.Int) *big.Int {
 		ratio.Add(ratio, ONE_UINT_256)
 	}
 
-	return ratio
+	return ratio, nil
 }
 
 // Ported function getTickAtSqrtRatio(uint160 sqrtPriceX96) internal pure returns (int24 tick)
 // Source: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/TickMath.sol
 // sqrtPriceX96 int96
 // return tick int24
+// Panics with ErrPriceOutOfRange if sqrtPriceX96 is out of [MIN_SQRT_RATIO, MAX_SQRT_RATIO), see TryGetTickAtSqrtRatio
 func GetTickAtSqrtRatio(sqrtPriceX96 *big.Int) *big.Int {
-	if sqrtPriceX96.Cmp(MIN_SQRT_RATIO) < 0 || sqrtPriceX96.Cmp(MAX_SQRT_RATIO) > 0 {
-		panic(fmt.Sprintf("ticks: sqrtPriceX96 %d out of interval [%d, %d]", sqrtPriceX96, MIN_SQRT_RATIO, MAX_SQRT_RATIO))
+	tick, err := TryGetTickAtSqrtRatio(sqrtPriceX96)
+	if err != nil {
+		panic(err)
+	}
+	return tick
+}
+
+// TryGetTickAtSqrtRatio is GetTickAtSqrtRatio which returns ErrPriceOutOfRange instead of panicking
+func TryGetTickAtSqrtRatio(sqrtPriceX96 *big.Int) (*big.Int, error) {
+	if sqrtPriceX96.Cmp(MIN_SQRT_RATIO) < 0 || sqrtPriceX96.Cmp(MAX_SQRT_RATIO) >= 0 {
+		return nil, fmt.Errorf("%w: sqrtPriceX96 %d out of interval [%d, %d)",
+			ErrPriceOutOfRange, sqrtPriceX96, MIN_SQRT_RATIO, MAX_SQRT_RATIO)
 	}
 
 	ratio := big.NewInt(0)
@@ -215,12 +248,12 @@ func GetTickAtSqrtRatio(sqrtPriceX96 *big.Int) *big.Int {
 	tickHi.Rsh(tickHi, 128)
 
 	if tickLow.Cmp(tickHi) == 0 {
-		return tickLow
+		return tickLow, nil
 	}
 
 	if GetSqrtRatioAtTick(tickHi).Cmp(sqrtPriceX96) <= 0 {
-		return tickHi
+		return tickHi, nil
 	}
 
-	return tickLow
+	return tickLow, nil
 }
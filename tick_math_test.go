@@ -1,6 +1,7 @@
 package uniswap_core
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 )
@@ -39,3 +40,112 @@ func TestGetSqrtRatioAtTick(t *testing.T) {
 		}
 	}
 }
+
+func TestTryGetSqrtRatioAtTick(t *testing.T) {
+	for _, tick := range []*big.Int{big.NewInt(0).Add(MAX_TICK, ONE_UINT_256), big.NewInt(0).Sub(MIN_TICK, ONE_UINT_256)} {
+		if _, err := TryGetSqrtRatioAtTick(tick); !errors.Is(err, ErrTickOutOfRange) {
+			t.Errorf("TryGetSqrtRatioAtTick(%d) error = %v; want %v", tick, err, ErrTickOutOfRange)
+		}
+	}
+
+	sqrtPrice, err := TryGetSqrtRatioAtTick(MAX_TICK)
+	if err != nil || sqrtPrice.Cmp(MAX_SQRT_RATIO) != 0 {
+		t.Errorf("TryGetSqrtRatioAtTick(%d) = %d, %v; want %d, nil", MAX_TICK, sqrtPrice, err, MAX_SQRT_RATIO)
+	}
+}
+
+func TestTryGetTickAtSqrtRatio(t *testing.T) {
+	for _, sqrtPrice := range []*big.Int{MAX_SQRT_RATIO, big.NewInt(0).Sub(MIN_SQRT_RATIO, ONE_UINT_256)} {
+		if _, err := TryGetTickAtSqrtRatio(sqrtPrice); !errors.Is(err, ErrPriceOutOfRange) {
+			t.Errorf("TryGetTickAtSqrtRatio(%d) error = %v; want %v", sqrtPrice, err, ErrPriceOutOfRange)
+		}
+	}
+
+	tick, err := TryGetTickAtSqrtRatio(big.NewInt(0).Sub(MAX_SQRT_RATIO, ONE_UINT_256))
+	refTick := big.NewInt(0).Sub(MAX_TICK, ONE_UINT_256)
+
+	if err != nil || tick.Cmp(refTick) != 0 {
+		t.Errorf("TryGetTickAtSqrtRatio(MAX_SQRT_RATIO - 1) = %d, %v; want %d, nil", tick, err, refTick)
+	}
+}
+
+// fuzzSqrtPrice maps the fuzzer bytes onto [MIN_SQRT_RATIO, MAX_SQRT_RATIO)
+func fuzzSqrtPrice(b []byte) *big.Int {
+	width := big.NewInt(0).Sub(MAX_SQRT_RATIO, MIN_SQRT_RATIO)
+	sqrtPrice := big.NewInt(0).SetBytes(b)
+	sqrtPrice.Mod(sqrtPrice, width)
+	return sqrtPrice.Add(sqrtPrice, MIN_SQRT_RATIO)
+}
+
+func FuzzGetSqrtRatioAtTick(f *testing.F) {
+	for _, tick := range []int32{0, 1, -1, 887271, 887272, 887273, -887271, -887272, -887273} {
+		f.Add(tick)
+	}
+
+	f.Fuzz(func(t *testing.T, tick int32) {
+		tickBig := big.NewInt(int64(tick))
+		sqrtPrice, err := TryGetSqrtRatioAtTick(tickBig)
+
+		if tickBig.Cmp(MIN_TICK) < 0 || tickBig.Cmp(MAX_TICK) > 0 {
+			if !errors.Is(err, ErrTickOutOfRange) {
+				t.Errorf("TryGetSqrtRatioAtTick(%d) error = %v; want %v", tick, err, ErrTickOutOfRange)
+			}
+			return
+		}
+
+		if err != nil {
+			t.Fatalf("TryGetSqrtRatioAtTick(%d): %s", tick, err)
+		}
+
+		if sqrtPrice.Cmp(MIN_SQRT_RATIO) < 0 || sqrtPrice.Cmp(MAX_SQRT_RATIO) > 0 {
+			t.Fatalf("TryGetSqrtRatioAtTick(%d) = %d; out of [MIN_SQRT_RATIO, MAX_SQRT_RATIO]", tick, sqrtPrice)
+		}
+
+		if tickBig.Cmp(MAX_TICK) == 0 {
+			return
+		}
+
+		// the price of the tick rounds back to the tick, one wei below rounds to the previous tick
+		if newTick := GetTickAtSqrtRatio(sqrtPrice); newTick.Cmp(tickBig) != 0 {
+			t.Errorf("GetTickAtSqrtRatio(%d) = %d; want %d", sqrtPrice, newTick, tick)
+		}
+
+		next := GetSqrtRatioAtTick(big.NewInt(int64(tick) + 1))
+		if next.Cmp(sqrtPrice) <= 0 {
+			t.Errorf("GetSqrtRatioAtTick(%d) = %d; want > %d", tick+1, next, sqrtPrice)
+		}
+
+		if tickBig.Cmp(MIN_TICK) == 0 {
+			return
+		}
+
+		below := big.NewInt(0).Sub(sqrtPrice, ONE_UINT_256)
+		if newTick := GetTickAtSqrtRatio(below); newTick.Int64() != int64(tick)-1 {
+			t.Errorf("GetTickAtSqrtRatio(%d) = %d; want %d", below, newTick, tick-1)
+		}
+	})
+}
+
+func FuzzGetTickAtSqrtRatio(f *testing.F) {
+	f.Add([]byte{})
+	f.Add(big.NewInt(0).Sub(MAX_SQRT_RATIO, MIN_SQRT_RATIO).Bytes())
+	f.Add(big.NewInt(0).Sub(MAX_SQRT_RATIO, big.NewInt(0).Add(MIN_SQRT_RATIO, ONE_UINT_256)).Bytes())
+	f.Add(big.NewInt(0).Sub(GetSqrtRatioAtTick(big.NewInt(0)), MIN_SQRT_RATIO).Bytes())
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		sqrtPrice := fuzzSqrtPrice(b)
+
+		tick, err := TryGetTickAtSqrtRatio(sqrtPrice)
+		if err != nil {
+			t.Fatalf("TryGetTickAtSqrtRatio(%d): %s", sqrtPrice, err)
+		}
+
+		// the greatest tick whose price is not above sqrtPrice
+		lower := GetSqrtRatioAtTick(tick)
+		upper := GetSqrtRatioAtTick(big.NewInt(0).Add(tick, ONE_UINT_256))
+
+		if lower.Cmp(sqrtPrice) > 0 || upper.Cmp(sqrtPrice) <= 0 {
+			t.Errorf("GetTickAtSqrtRatio(%d) = %d; want sqrt price in [%d, %d)", sqrtPrice, tick, lower, upper)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package uniswap_core
+
+import (
+	"fmt"
+
+	"github.com/holiman/uint256"
+)
+
+var aU256 [19]uint256.Int
+var init0U256 uint256.Int
+var init1U256 uint256.Int
+var sqrt10001U256 uint256.Int
+var lowerErrBoundU256 uint256.Int
+var upperErrBoundU256 uint256.Int
+
+const minTickInt32 int32 = -887272
+const maxTickInt32 int32 = -minTickInt32
+
+func init() {
+	for i := 0; i < len(A); i++ {
+		aU256[i].SetFromBig(A[i])
+	}
+
+	init0U256.SetFromBig(INIT0)
+	init1U256.SetFromBig(INIT1)
+	sqrt10001U256.SetFromBig(SQRT_10001)
+	lowerErrBoundU256.SetFromBig(LOWER_ERR_BOUND)
+	upperErrBoundU256.SetFromBig(UPPER_ERR_BOUND)
+}
+
+// Fixed-width GetSqrtRatioAtTick, stores the Q64.96 sqrt price of the tick into z
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/TickMath.sol
+// z	uint256.Int	the result holder
+// tick	int32	int24 tick
+// return uint160 sqrtPriceX96, ErrTickOutOfRange if |tick| > MAX_TICK
+func GetSqrtRatioAtTickU256(z *uint256.Int, tick int32) (*uint256.Int, error) {
+	absTick := tick
+	if absTick < 0 {
+		absTick = -absTick
+	}
+
+	if absTick > maxTickInt32 {
+		return nil, fmt.Errorf("%w: tick %d out of interval [%d, %d]", ErrTickOutOfRange, tick, minTickInt32, maxTickInt32)
+	}
+
+	if absTick&1 != 0 {
+		z.Set(&init0U256)
+	} else {
+		z.Set(&init1U256)
+	}
+
+	for i := 0; i < len(aU256); i++ {
+		if absTick&(2<<i) != 0 {
+			z.Mul(z, &aU256[i])
+			z.Rsh(z, 128)
+		}
+	}
+
+	if tick > 0 {
+		z.Div(&maxUint256U256, z)
+	}
+
+	roundUp := z[0]&0xFFFFFFFF != 0
+	z.Rsh(z, 32)
+
+	if roundUp {
+		z.AddUint64(z, 1)
+	}
+
+	return z, nil
+}
+
+// Fixed-width GetTickAtSqrtRatio
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/TickMath.sol
+// sqrtPriceX96	uint256.Int	uint160 sqrt price
+// return int24 tick, ErrPriceOutOfRange if sqrtPriceX96 is out of [MIN_SQRT_RATIO, MAX_SQRT_RATIO)
+func GetTickAtSqrtRatioU256(sqrtPriceX96 *uint256.Int) (int32, error) {
+	if sqrtPriceX96.Lt(&minSqrtRatioU256) || !sqrtPriceX96.Lt(&maxSqrtRatioU256) {
+		return 0, fmt.Errorf("%w: sqrtPriceX96 %d out of interval [%d, %d)",
+			ErrPriceOutOfRange, sqrtPriceX96.ToBig(), MIN_SQRT_RATIO, MAX_SQRT_RATIO)
+	}
+
+	var ratio, r, log2, f uint256.Int
+
+	ratio.Lsh(sqrtPriceX96, 32)
+	msb := ratio.BitLen() - 1
+
+	if msb >= 128 {
+		r.Rsh(&ratio, uint(msb-127))
+	} else {
+		r.Lsh(&ratio, uint(127-msb))
+	}
+
+	// log_2 is a signed value, two's complement arithmetic keeps it correct
+	log2.SetUint64(uint64(msb))
+	log2.Sub(&log2, f.SetUint64(128))
+	log2.Lsh(&log2, 64)
+
+	for i := 0; i < 14; i++ {
+		r.Mul(&r, &r)
+		r.Rsh(&r, 127)
+		bit := r[2] & 1
+		f.SetUint64(bit)
+		log2.Or(&log2, f.Lsh(&f, uint(63-i)))
+		r.Rsh(&r, uint(bit))
+	}
+
+	log2.Mul(&log2, &sqrt10001U256)
+
+	var tickLow, tickHi uint256.Int
+
+	tickLow.Sub(&log2, &lowerErrBoundU256)
+	tickLow.SRsh(&tickLow, 128)
+
+	tickHi.Add(&log2, &upperErrBoundU256)
+	tickHi.SRsh(&tickHi, 128)
+
+	low := int32(int64(tickLow[0]))
+	hi := int32(int64(tickHi[0]))
+
+	if low == hi {
+		return low, nil
+	}
+
+	if _, err := GetSqrtRatioAtTickU256(&f, hi); err == nil && !sqrtPriceX96.Lt(&f) {
+		return hi, nil
+	}
+
+	return low, nil
+}
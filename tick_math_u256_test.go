@@ -0,0 +1,108 @@
+package uniswap_core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+)
+
+func TestGetSqrtRatioAtTickU256(t *testing.T) {
+	var ratio uint256.Int
+
+	for tick := minTickInt32; tick <= maxTickInt32; tick += 997 {
+		want := GetSqrtRatioAtTick(big.NewInt(int64(tick)))
+
+		if _, err := GetSqrtRatioAtTickU256(&ratio, tick); err != nil || ratio.ToBig().Cmp(want) != 0 {
+			t.Errorf("GetSqrtRatioAtTickU256(%d) = %d, %v; want %d", tick, &ratio, err, want)
+		}
+	}
+
+	for _, tick := range []int32{minTickInt32, -1, 0, 1, maxTickInt32} {
+		want := GetSqrtRatioAtTick(big.NewInt(int64(tick)))
+
+		if _, err := GetSqrtRatioAtTickU256(&ratio, tick); err != nil || ratio.ToBig().Cmp(want) != 0 {
+			t.Errorf("GetSqrtRatioAtTickU256(%d) = %d, %v; want %d", tick, &ratio, err, want)
+		}
+	}
+
+	for _, tick := range []int32{minTickInt32 - 1, maxTickInt32 + 1} {
+		if _, err := GetSqrtRatioAtTickU256(&ratio, tick); !errors.Is(err, ErrTickOutOfRange) {
+			t.Errorf("GetSqrtRatioAtTickU256(%d) error = %v; want %v", tick, err, ErrTickOutOfRange)
+		}
+	}
+}
+
+func TestGetTickAtSqrtRatioU256(t *testing.T) {
+	var sqrtPriceX96 uint256.Int
+
+	prices := []*big.Int{
+		MIN_SQRT_RATIO,
+		big.NewInt(0).Sub(MAX_SQRT_RATIO, ONE_UINT_256),
+		big.NewInt(0).Lsh(ONE_UINT_256, 96),
+	}
+
+	for tick := minTickInt32; tick <= maxTickInt32; tick += 997 {
+		price := GetSqrtRatioAtTick(big.NewInt(int64(tick)))
+		prices = append(prices, price, big.NewInt(0).Add(price, ONE_UINT_256), big.NewInt(0).Sub(price, ONE_UINT_256))
+	}
+
+	for _, price := range prices {
+		want, err := TryGetTickAtSqrtRatio(price)
+		if err != nil {
+			continue
+		}
+
+		sqrtPriceX96.SetFromBig(price)
+
+		if tick, err := GetTickAtSqrtRatioU256(&sqrtPriceX96); err != nil || int64(tick) != want.Int64() {
+			t.Errorf("GetTickAtSqrtRatioU256(%d) = %d, %v; want %d", price, tick, err, want)
+		}
+	}
+
+	for _, price := range []*big.Int{big.NewInt(0).Sub(MIN_SQRT_RATIO, ONE_UINT_256), MAX_SQRT_RATIO} {
+		sqrtPriceX96.SetFromBig(price)
+
+		if _, err := GetTickAtSqrtRatioU256(&sqrtPriceX96); !errors.Is(err, ErrPriceOutOfRange) {
+			t.Errorf("GetTickAtSqrtRatioU256(%d) error = %v; want %v", price, err, ErrPriceOutOfRange)
+		}
+	}
+}
+
+func BenchmarkGetSqrtRatioAtTick(b *testing.B) {
+	tick := big.NewInt(-123456)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetSqrtRatioAtTick(tick)
+	}
+}
+
+func BenchmarkGetSqrtRatioAtTickU256(b *testing.B) {
+	var ratio uint256.Int
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetSqrtRatioAtTickU256(&ratio, -123456)
+	}
+}
+
+func BenchmarkGetTickAtSqrtRatio(b *testing.B) {
+	sqrtPriceX96 := GetSqrtRatioAtTick(big.NewInt(-123456))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetTickAtSqrtRatio(sqrtPriceX96)
+	}
+}
+
+func BenchmarkGetTickAtSqrtRatioU256(b *testing.B) {
+	var sqrtPriceX96 uint256.Int
+	sqrtPriceX96.SetFromBig(GetSqrtRatioAtTick(big.NewInt(-123456)))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetTickAtSqrtRatioU256(&sqrtPriceX96)
+	}
+}
@@ -1,17 +1,32 @@
 package uniswap_core
 
 import (
+	"fmt"
 	"math/big"
 )
 
+// TickReader provides the initialized ticks to the swap loop.
+// The returned values may be shared with the reader's state, e.g. by TickList, so the callers must not change them
 type TickReader interface {
+	// NextInitializedTick returns the next tick to step to, less than or equal to the tick (zeroForOne)
+	// or greater than the tick, and whether it is initialized
 	NextInitializedTick(tick *big.Int, zeroForOne bool) (*big.Int, bool)
+	// GetLiquidityNet returns the liquidity added when the tick is crossed from left to right
 	GetLiquidityNet(tick *big.Int) *big.Int
 }
 
+// TickStorage keeps the ticks of the pool by index. The ticks may be shared with the forks of the storage,
+// so they are changed only by Update, Clear and Cross, which copy a shared tick before the first change
 type TickStorage struct {
 	Ticks       map[int64]*Tick
 	TickSpacing *big.Int
+	// the maximum gross liquidity of a tick, see TickSpacingToMaxLiquidityPerTick
+	MaxLiquidityPerTick *big.Int
+	// Strict enforces the bit widths and the revert conditions of Tick.sol in TryUpdate, see TryAddLiquidityDelta.
+	// It is off by default, is inherited by the forks and follows PoolSimulator.Strict in the simulator
+	Strict bool
+	// the indexes of the ticks which are not shared with a fork
+	owned map[int64]bool
 }
 
 func (t TickStorage) IsInitialized(tickKey *big.Int) bool {
@@ -27,22 +42,87 @@ func (t TickStorage) IsInitialized(tickKey *big.Int) bool {
 func NewTickStorage(ticks []Tick, tickSpacing *big.Int) *TickStorage {
 	t := new(TickStorage)
 	t.TickSpacing = big.NewInt(tickSpacing.Int64())
+	t.MaxLiquidityPerTick = TickSpacingToMaxLiquidityPerTick(t.TickSpacing)
 	t.Ticks = make(map[int64]*Tick)
+	t.owned = make(map[int64]bool)
 
-	for i, tick := range ticks {
-		t.Ticks[tick.TickIdx.Val.Int64()] = &ticks[i]
+	for i := range ticks {
+		key := ticks[i].TickIdx.Val.Int64()
+		t.Ticks[key] = copyTick(&ticks[i])
+		t.owned[key] = true
 	}
 	return t
 }
 
+// copyTick returns a copy of the tick with its own values of the fields changed by TickStorage
+func copyTick(tick *Tick) *Tick {
+	c := *tick
+
+	for _, field := range []*BigInt{
+		&c.TickIdx,
+		&c.LiquidityGross,
+		&c.LiquidityNet,
+		&c.FeeGrowthOutside0X128,
+		&c.FeeGrowthOutside1X128} {
+		val := big.NewInt(0)
+		if field.Val != nil {
+			val.Set(field.Val)
+		}
+		field.Val = val
+	}
+	return &c
+}
+
+// Fork returns a copy-on-write view of the storage, the ticks are shared until either storage changes them,
+// so the fork and the storage change independently. Only the map of the ticks is copied
+func (t *TickStorage) Fork() *TickStorage {
+	f := &TickStorage{
+		Ticks:               make(map[int64]*Tick, len(t.Ticks)),
+		TickSpacing:         big.NewInt(0).Set(t.TickSpacing),
+		MaxLiquidityPerTick: t.MaxLiquidityPerTick,
+		Strict:              t.Strict,
+		owned:               make(map[int64]bool)}
+
+	for key, tick := range t.Ticks {
+		f.Ticks[key] = tick
+	}
+
+	// the ticks owned so far are shared now
+	t.owned = make(map[int64]bool)
+	return f
+}
+
+// mutable returns the tick which may be changed, copying it if it is shared with a fork, nil if there is no tick
+func (t *TickStorage) mutable(key int64) *Tick {
+	if t.owned == nil {
+		t.owned = make(map[int64]bool)
+	}
+
+	tick, ok := t.Ticks[key]
+	if !ok {
+		return nil
+	}
+
+	if !t.owned[key] {
+		tick = copyTick(tick)
+		t.Ticks[key] = tick
+		t.owned[key] = true
+	}
+	return tick
+}
+
+// NextInitializedTick returns the nearest initialized tick less than or equal to the tick (zeroForOne),
+// or greater than the tick, and MIN_TICK/MAX_TICK if there is no such tick.
+// Unlike TickBitmap it does not stop at the word boundaries, so the swap takes fewer steps than the pool does
+// and the amounts may differ from the contract's ones by the rounding of the steps. It scans the tick spacings
+// one by one, the swaps matching the contract step through NewTickBitmap of the storage, as PoolSimulator does
 func (t TickStorage) NextInitializedTick(tick *big.Int, zeroForOne bool) (*big.Int, bool) {
+	// the Euclidean division rounds the negative ticks towards negative infinity
 	tickNext := big.NewInt(0).Set(tick)
 	tickNext.Div(tickNext, t.TickSpacing)
 	tickNext.Mul(tickNext, t.TickSpacing)
 
-	if zeroForOne && tickNext.Cmp(tick) >= 0 {
-		tickNext.Sub(tickNext, t.TickSpacing)
-	} else if !zeroForOne && tickNext.Cmp(tick) <= 0 {
+	if !zeroForOne {
 		tickNext.Add(tickNext, t.TickSpacing)
 	}
 
@@ -78,3 +158,228 @@ func (t TickStorage) GetLiquidityNet(tick *big.Int) *big.Int {
 	}
 	return nil
 }
+
+func newTick(tickIdx *big.Int) *Tick {
+	return &Tick{
+		TickIdx:               BigInt{Val: big.NewInt(0).Set(tickIdx)},
+		LiquidityGross:        BigInt{Val: big.NewInt(0)},
+		LiquidityNet:          BigInt{Val: big.NewInt(0)},
+		FeeGrowthOutside0X128: BigInt{Val: big.NewInt(0)},
+		FeeGrowthOutside1X128: BigInt{Val: big.NewInt(0)},
+	}
+}
+
+// Derives max liquidity per tick from given tick spacing
+// tickSpacing	big.Int	The amount of required tick separation, realized in multiples of tickSpacing
+// e.g., a tickSpacing of 3 requires ticks to be initialized every 3rd tick i.e., ..., -6, -3, 0, 3, 6, ...
+// return maxLiquidityPerTick	The max liquidity per tick
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/Tick.sol
+func TickSpacingToMaxLiquidityPerTick(tickSpacing *big.Int) (maxLiquidityPerTick *big.Int) {
+	// the division of Solidity truncates towards zero
+	minTick := big.NewInt(0).Quo(MIN_TICK, tickSpacing)
+	minTick.Mul(minTick, tickSpacing)
+	maxTick := big.NewInt(0).Quo(MAX_TICK, tickSpacing)
+	maxTick.Mul(maxTick, tickSpacing)
+
+	numTicks := big.NewInt(0).Sub(maxTick, minTick)
+	numTicks.Quo(numTicks, tickSpacing)
+	numTicks.Add(numTicks, ONE_UINT_256)
+
+	maxLiquidityPerTick = big.NewInt(0).Quo(MAX_UINT_128, numTicks)
+	return
+}
+
+// Updates a tick and returns true if the tick was flipped from initialized to uninitialized, or vice versa.
+// Panics if the tick cannot be updated, e.g. over MaxLiquidityPerTick, see TryUpdate
+// tick	big.Int	The tick that will be updated
+// tickCurrent	big.Int	The current tick
+// liquidityDelta	big.Int	A new amount of liquidity to be added (subtracted) when tick is crossed from left to right (right to left)
+// feeGrowthGlobal0X128	big.Int	The all-time global fee growth, per unit of liquidity, in token0
+// feeGrowthGlobal1X128	big.Int	The all-time global fee growth, per unit of liquidity, in token1
+// upper	bool	true for updating a position's upper tick, or false for updating a position's lower tick
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/Tick.sol
+func (t *TickStorage) Update(
+	tick *big.Int,
+	tickCurrent *big.Int,
+	liquidityDelta *big.Int,
+	feeGrowthGlobal0X128 *big.Int,
+	feeGrowthGlobal1X128 *big.Int,
+	upper bool) (flipped bool) {
+	flipped, err := t.TryUpdate(tick, tickCurrent, liquidityDelta, feeGrowthGlobal0X128, feeGrowthGlobal1X128, upper)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// TryUpdate is Update which returns an error instead of panicking, the tick is left untouched on error:
+// ErrOverflow ('LO') if the gross liquidity exceeds MaxLiquidityPerTick and, if Strict, the errors of the liquidity math
+func (t *TickStorage) TryUpdate(
+	tick *big.Int,
+	tickCurrent *big.Int,
+	liquidityDelta *big.Int,
+	feeGrowthGlobal0X128 *big.Int,
+	feeGrowthGlobal1X128 *big.Int,
+	upper bool) (flipped bool, err error) {
+	liquidityGrossAfter, liquidityNetAfter, err := t.updatedLiquidity(tick, liquidityDelta, upper)
+	if err != nil {
+		return
+	}
+
+	key := tick.Int64()
+	info := t.mutable(key)
+	if info == nil {
+		info = newTick(tick)
+		t.Ticks[key] = info
+		t.owned[key] = true
+	}
+
+	liquidityGrossBefore := info.LiquidityGross.Val
+
+	flipped = (liquidityGrossAfter.Cmp(ZERO_UINT_256) == 0) != (liquidityGrossBefore.Cmp(ZERO_UINT_256) == 0)
+
+	if liquidityGrossBefore.Cmp(ZERO_UINT_256) == 0 {
+		// by convention, we assume that all growth before a tick was initialized happened _below_ the tick
+		if tick.Cmp(tickCurrent) <= 0 {
+			info.FeeGrowthOutside0X128.Val.Set(feeGrowthGlobal0X128)
+			info.FeeGrowthOutside1X128.Val.Set(feeGrowthGlobal1X128)
+		}
+	}
+
+	info.LiquidityGross.Val = liquidityGrossAfter
+	info.LiquidityNet.Val.Set(liquidityNetAfter)
+
+	return
+}
+
+// Returns the gross and net liquidity of the tick after the update without changing the tick,
+// the errors are the ones of the update: 'LO' and, if Strict, 'LA', 'LS' or int128 overflow of the net liquidity
+func (t TickStorage) updatedLiquidity(
+	tick *big.Int,
+	liquidityDelta *big.Int,
+	upper bool) (liquidityGrossAfter *big.Int, liquidityNetAfter *big.Int, err error) {
+	liquidityGrossBefore, liquidityNetBefore := ZERO_UINT_256, ZERO_UINT_256
+	if info, ok := t.Ticks[tick.Int64()]; ok {
+		liquidityGrossBefore, liquidityNetBefore = info.LiquidityGross.Val, info.LiquidityNet.Val
+	}
+
+	if liquidityGrossAfter, err = TryAddLiquidityDelta(liquidityGrossBefore, liquidityDelta, t.Strict); err != nil {
+		return
+	}
+
+	if t.MaxLiquidityPerTick != nil && liquidityGrossAfter.Cmp(t.MaxLiquidityPerTick) > 0 {
+		return nil, nil, fmt.Errorf("%w: TickStorage: liquidity %d of tick %d exceeds max liquidity per tick %d",
+			ErrOverflow, liquidityGrossAfter, tick, t.MaxLiquidityPerTick)
+	}
+
+	// when the lower (upper) tick is crossed left to right (right to left), liquidity must be added (removed)
+	liquidityNetAfter = big.NewInt(0)
+	if upper {
+		liquidityNetAfter.Sub(liquidityNetBefore, liquidityDelta)
+	} else {
+		liquidityNetAfter.Add(liquidityNetBefore, liquidityDelta)
+	}
+
+	if t.Strict {
+		if _, err = ToInt128(liquidityNetAfter); err != nil {
+			return nil, nil, err
+		}
+	}
+	return
+}
+
+// Clears tick data
+// tick	big.Int	The tick that will be cleared
+func (t *TickStorage) Clear(tick *big.Int) {
+	delete(t.Ticks, tick.Int64())
+	delete(t.owned, tick.Int64())
+}
+
+func (t TickStorage) getFeeGrowthOutside(tick *big.Int) (feeGrowthOutside0X128 *big.Int, feeGrowthOutside1X128 *big.Int) {
+	feeGrowthOutside0X128, feeGrowthOutside1X128 = ZERO_UINT_256, ZERO_UINT_256
+
+	if data, ok := t.Ticks[tick.Int64()]; ok {
+		if data.FeeGrowthOutside0X128.Val != nil {
+			feeGrowthOutside0X128 = data.FeeGrowthOutside0X128.Val
+		}
+		if data.FeeGrowthOutside1X128.Val != nil {
+			feeGrowthOutside1X128 = data.FeeGrowthOutside1X128.Val
+		}
+	}
+	return
+}
+
+// GetFeeGrowthInside returns the all-time fee growth in token0 and token1, per unit of liquidity,
+// inside the [tickLower, tickUpper] range at the state of slot0.
+// Uninitialized ticks are treated as having no fee growth outside
+func (t TickStorage) GetFeeGrowthInside(
+	tickLower *big.Int,
+	tickUpper *big.Int,
+	slot0 *Slot0) (feeGrowthInside0X128 *big.Int, feeGrowthInside1X128 *big.Int) {
+	return t.getFeeGrowthInside(tickLower, tickUpper, slot0.TickCurrent,
+		slot0.FeeGrowthGlobal0X128, slot0.FeeGrowthGlobal1X128)
+}
+
+// Retrieves fee growth data
+// tickLower	big.Int	The lower tick boundary of the position
+// tickUpper	big.Int	The upper tick boundary of the position
+// tickCurrent	big.Int	The current tick
+// feeGrowthGlobal0X128	big.Int	The all-time global fee growth, per unit of liquidity, in token0
+// feeGrowthGlobal1X128	big.Int	The all-time global fee growth, per unit of liquidity, in token1
+// feeGrowthInside0X128	big.Int	The all-time fee growth in token0, per unit of liquidity, inside the position's tick boundaries
+// feeGrowthInside1X128	big.Int	The all-time fee growth in token1, per unit of liquidity, inside the position's tick boundaries
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/Tick.sol
+func (t TickStorage) getFeeGrowthInside(
+	tickLower *big.Int,
+	tickUpper *big.Int,
+	tickCurrent *big.Int,
+	feeGrowthGlobal0X128 *big.Int,
+	feeGrowthGlobal1X128 *big.Int) (feeGrowthInside0X128 *big.Int, feeGrowthInside1X128 *big.Int) {
+	lowerOutside0X128, lowerOutside1X128 := t.getFeeGrowthOutside(tickLower)
+	upperOutside0X128, upperOutside1X128 := t.getFeeGrowthOutside(tickUpper)
+
+	// calculate fee growth below
+	var feeGrowthBelow0X128, feeGrowthBelow1X128 *big.Int
+	if tickCurrent.Cmp(tickLower) >= 0 {
+		feeGrowthBelow0X128 = lowerOutside0X128
+		feeGrowthBelow1X128 = lowerOutside1X128
+	} else {
+		feeGrowthBelow0X128 = SubUint256(feeGrowthGlobal0X128, lowerOutside0X128)
+		feeGrowthBelow1X128 = SubUint256(feeGrowthGlobal1X128, lowerOutside1X128)
+	}
+
+	// calculate fee growth above
+	var feeGrowthAbove0X128, feeGrowthAbove1X128 *big.Int
+	if tickCurrent.Cmp(tickUpper) < 0 {
+		feeGrowthAbove0X128 = upperOutside0X128
+		feeGrowthAbove1X128 = upperOutside1X128
+	} else {
+		feeGrowthAbove0X128 = SubUint256(feeGrowthGlobal0X128, upperOutside0X128)
+		feeGrowthAbove1X128 = SubUint256(feeGrowthGlobal1X128, upperOutside1X128)
+	}
+
+	feeGrowthInside0X128 = SubUint256(SubUint256(feeGrowthGlobal0X128, feeGrowthBelow0X128), feeGrowthAbove0X128)
+	feeGrowthInside1X128 = SubUint256(SubUint256(feeGrowthGlobal1X128, feeGrowthBelow1X128), feeGrowthAbove1X128)
+	return
+}
+
+// Transitions to next tick as needed by price movement
+// tick	big.Int	The destination tick of the transition
+// feeGrowthGlobal0X128	big.Int	The all-time global fee growth, per unit of liquidity, in token0
+// feeGrowthGlobal1X128	big.Int	The all-time global fee growth, per unit of liquidity, in token1
+// liquidityNet	big.Int	The amount of liquidity added (subtracted) when tick is crossed from left to right (right to left)
+// Origin: https://github.com/Uniswap/v3-core/blob/main/contracts/libraries/Tick.sol
+func (t *TickStorage) Cross(
+	tick *big.Int,
+	feeGrowthGlobal0X128 *big.Int,
+	feeGrowthGlobal1X128 *big.Int) (liquidityNet *big.Int) {
+	info := t.mutable(tick.Int64())
+	if info == nil {
+		return big.NewInt(0)
+	}
+
+	info.FeeGrowthOutside0X128.Val = SubUint256(feeGrowthGlobal0X128, info.FeeGrowthOutside0X128.Val)
+	info.FeeGrowthOutside1X128.Val = SubUint256(feeGrowthGlobal1X128, info.FeeGrowthOutside1X128.Val)
+
+	return big.NewInt(0).Set(info.LiquidityNet.Val)
+}
@@ -1,6 +1,7 @@
 package uniswap_core
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 )
@@ -37,6 +38,14 @@ func TestNewTickStorage(t *testing.T) {
 	if ts.Ticks[key0].TickIdx.Val.Cmp(ts.Ticks[key1].TickIdx.Val) == 0 {
 		t.Errorf("Incorrect assign map elements")
 	}
+
+	// the storage owns copies of the ticks
+	ts.Update(data[1].TickIdx.Val, big.NewInt(0), big.NewInt(5), big.NewInt(0), big.NewInt(0), false)
+	ts.Cross(data[1].TickIdx.Val, big.NewInt(7), big.NewInt(7))
+
+	if data[1].LiquidityGross.Val.Int64() != 1 || data[1].FeeGrowthOutside0X128.Val.Sign() != 0 {
+		t.Errorf("TickStorage changed the tick %d of the caller", key1)
+	}
 }
 
 func TestGetLiquidityNet(t *testing.T) {
@@ -90,4 +99,222 @@ func TestNextInitializedTick(t *testing.T) {
 	if initialized != true {
 		t.Errorf("initialized = %t; want %t", initialized, true)
 	}
+
+	// the tick itself is the next one to the left like in TickBitmap, the ticks of uninitialized
+	// data[1] and the ones in between the spacing are skipped
+	cases := []struct {
+		tick       int64
+		zeroForOne bool
+		want       int64
+	}{
+		{data[4].TickIdx.Val.Int64(), true, data[4].TickIdx.Val.Int64()},
+		{data[4].TickIdx.Val.Int64() + 1, true, data[4].TickIdx.Val.Int64()},
+		{data[4].TickIdx.Val.Int64() - 1, true, data[3].TickIdx.Val.Int64()},
+		{data[2].TickIdx.Val.Int64() - 1, true, data[0].TickIdx.Val.Int64()},
+		{data[4].TickIdx.Val.Int64() - 1, false, data[4].TickIdx.Val.Int64()},
+	}
+
+	for _, c := range cases {
+		tickNext, initialized := ts.NextInitializedTick(big.NewInt(c.tick), c.zeroForOne)
+
+		if tickNext.Int64() != c.want || !initialized {
+			t.Errorf("NextInitializedTick(%d, %t) = %d, %t; want %d, true", c.tick, c.zeroForOne, tickNext, initialized, c.want)
+		}
+	}
+}
+
+func TestTickSpacingToMaxLiquidityPerTick(t *testing.T) {
+	cases := []struct {
+		tickSpacing int64
+		want        string
+	}{
+		{10, "1917569901783203986719870431555990"},
+		{60, "11505743598341114571880798222544994"},
+		{200, "38350317471085141830651933667504588"},
+		{887272, "113427455640312821154458202477256070485"},
+		{2302, "441351967472034323558203122479595605"},
+	}
+
+	for _, c := range cases {
+		want, _ := big.NewInt(0).SetString(c.want, 10)
+
+		if got := TickSpacingToMaxLiquidityPerTick(big.NewInt(c.tickSpacing)); got.Cmp(want) != 0 {
+			t.Errorf("TickSpacingToMaxLiquidityPerTick(%d) = %d; want %d", c.tickSpacing, got, want)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ts := NewTickStorage(nil, big.NewInt(1))
+	ts.MaxLiquidityPerTick = big.NewInt(3)
+
+	zero := big.NewInt(0)
+	update := func(tick int64, tickCurrent int64, liquidityDelta int64, upper bool) (bool, error) {
+		return ts.TryUpdate(big.NewInt(tick), big.NewInt(tickCurrent), big.NewInt(liquidityDelta),
+			big.NewInt(1), big.NewInt(2), upper)
+	}
+
+	steps := []struct {
+		liquidityDelta int64
+		upper          bool
+		flipped        bool
+	}{
+		{1, false, true},
+		{1, true, false},
+		{-1, false, false},
+		{-1, true, true},
+	}
+
+	for _, s := range steps {
+		if flipped, err := update(0, 1, s.liquidityDelta, s.upper); err != nil || flipped != s.flipped {
+			t.Errorf("TickStorage.TryUpdate(0, 1, %d, %t) = %t, %v; want %t, nil", s.liquidityDelta, s.upper, flipped, err, s.flipped)
+		}
+	}
+
+	// the growth outside is set once, when the tick below or at the current tick is initialized
+	outside0, outside1 := ts.getFeeGrowthOutside(zero)
+	if outside0.Int64() != 1 || outside1.Int64() != 2 {
+		t.Errorf("tick 0 FeeGrowthOutsideX128 = %d, %d; want 1, 2", outside0, outside1)
+	}
+
+	if _, err := update(1, 0, 1, false); err != nil {
+		t.Fatalf("TickStorage.TryUpdate(1, 0, 1): %s", err)
+	}
+
+	if outside0, outside1 := ts.getFeeGrowthOutside(big.NewInt(1)); outside0.Sign() != 0 || outside1.Sign() != 0 {
+		t.Errorf("tick 1 FeeGrowthOutsideX128 = %d, %d; want 0, 0", outside0, outside1)
+	}
+
+	// the net liquidity depends on the side of the position
+	if _, err := update(1, 0, 2, true); err != nil {
+		t.Fatalf("TickStorage.TryUpdate(1, 0, 2, true): %s", err)
+	}
+
+	info := ts.Ticks[1]
+	if info.LiquidityGross.Val.Int64() != 3 || info.LiquidityNet.Val.Int64() != -1 {
+		t.Errorf("tick 1 liquidity = %d, %d; want 3, -1", info.LiquidityGross.Val, info.LiquidityNet.Val)
+	}
+
+	// 'LO', the tick is not changed
+	if _, err := update(1, 0, 1, false); !errors.Is(err, ErrOverflow) {
+		t.Errorf("TickStorage.TryUpdate(1, 0, 1) error = %v; want %v", err, ErrOverflow)
+	}
+
+	if info.LiquidityGross.Val.Int64() != 3 || info.LiquidityNet.Val.Int64() != -1 {
+		t.Errorf("tick 1 liquidity = %d, %d; want 3, -1", info.LiquidityGross.Val, info.LiquidityNet.Val)
+	}
+
+	// 'LS' of the gross liquidity in the strict mode, the tick is not changed
+	ts.Strict = true
+	if _, err := update(1, 0, -4, false); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("TickStorage.TryUpdate(1, 0, -4) error = %v; want %v", err, ErrInsufficientLiquidity)
+	}
+
+	if info.LiquidityGross.Val.Int64() != 3 || info.LiquidityNet.Val.Int64() != -1 {
+		t.Errorf("tick 1 liquidity = %d, %d; want 3, -1", info.LiquidityGross.Val, info.LiquidityNet.Val)
+	}
+
+	if fork := ts.Fork(); !fork.Strict {
+		t.Errorf("TickStorage.Fork().Strict = false; want true")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("TickStorage.Update() over the max liquidity per tick must panic")
+		}
+	}()
+	ts.Update(big.NewInt(1), zero, big.NewInt(1), zero, zero, false)
+}
+
+func TestClear(t *testing.T) {
+	ts := NewTickStorage(nil, big.NewInt(1))
+	ts.Update(big.NewInt(2), big.NewInt(3), big.NewInt(3), big.NewInt(1), big.NewInt(2), false)
+	ts.Clear(big.NewInt(2))
+
+	if _, ok := ts.Ticks[2]; ok || ts.IsInitialized(big.NewInt(2)) {
+		t.Errorf("TickStorage.Clear(2) left the tick data")
+	}
+
+	if liquidityNet := ts.Cross(big.NewInt(2), big.NewInt(1), big.NewInt(2)); liquidityNet.Sign() != 0 {
+		t.Errorf("Cross(2) of the cleared tick = %d; want 0", liquidityNet)
+	}
+}
+
+func TestCross(t *testing.T) {
+	tickSpacing := big.NewInt(60)
+	data := []Tick{{
+		TickIdx:               BigInt{Val: big.NewInt(60)},
+		LiquidityGross:        BigInt{Val: big.NewInt(100)},
+		LiquidityNet:          BigInt{Val: big.NewInt(-100)},
+		FeeGrowthOutside0X128: BigInt{Val: big.NewInt(3)},
+		FeeGrowthOutside1X128: BigInt{Val: big.NewInt(5)},
+	}}
+
+	ts := NewTickStorage(data, tickSpacing)
+	liqNet := ts.Cross(big.NewInt(60), big.NewInt(10), big.NewInt(2))
+
+	if liqNet.Int64() != -100 {
+		t.Errorf("Cross(60) = %d; want %d", liqNet, -100)
+	}
+
+	outside0 := ts.Ticks[60].FeeGrowthOutside0X128.Val
+	outside1 := ts.Ticks[60].FeeGrowthOutside1X128.Val
+	refOutside1 := big.NewInt(0).Sub(MAX_UINT_256, big.NewInt(2))
+
+	if outside0.Int64() != 7 || outside1.Cmp(refOutside1) != 0 {
+		t.Errorf("FeeGrowthOutsideX128 = %d, %d; want %d, %d", outside0, outside1, 7, refOutside1)
+	}
+}
+
+func TestGetFeeGrowthInside(t *testing.T) {
+	tickWithOutside := func(idx int64, outside0 *big.Int, outside1 *big.Int) Tick {
+		return Tick{
+			TickIdx:               BigInt{Val: big.NewInt(idx)},
+			LiquidityGross:        BigInt{Val: big.NewInt(1)},
+			LiquidityNet:          BigInt{Val: big.NewInt(0)},
+			FeeGrowthOutside0X128: BigInt{Val: outside0},
+			FeeGrowthOutside1X128: BigInt{Val: outside1},
+		}
+	}
+
+	cases := []struct {
+		ticks       []Tick
+		tickCurrent int64
+		ref0        *big.Int
+		ref1        *big.Int
+	}{
+		// two uninitialized ticks, the current tick is inside
+		{nil, 0, big.NewInt(15), big.NewInt(15)},
+		// two uninitialized ticks, the current tick is above
+		{nil, 4, big.NewInt(0), big.NewInt(0)},
+		// two uninitialized ticks, the current tick is below
+		{nil, -4, big.NewInt(0), big.NewInt(0)},
+		// subtracts upper tick if below
+		{[]Tick{tickWithOutside(2, big.NewInt(2), big.NewInt(3))}, 0, big.NewInt(13), big.NewInt(12)},
+		// subtracts lower tick if above
+		{[]Tick{tickWithOutside(-2, big.NewInt(2), big.NewInt(3))}, 0, big.NewInt(13), big.NewInt(12)},
+		// subtracts upper and lower tick if inside
+		{[]Tick{
+			tickWithOutside(-2, big.NewInt(2), big.NewInt(3)),
+			tickWithOutside(2, big.NewInt(4), big.NewInt(1))}, 0, big.NewInt(9), big.NewInt(11)},
+		// works correctly with overflow on inside tick
+		{[]Tick{
+			tickWithOutside(-2, big.NewInt(0).Sub(MAX_UINT_256, big.NewInt(3)), big.NewInt(0).Sub(MAX_UINT_256, big.NewInt(2))),
+			tickWithOutside(2, big.NewInt(3), big.NewInt(5))}, 0, big.NewInt(16), big.NewInt(13)},
+	}
+
+	for i, c := range cases {
+		ts := NewTickStorage(c.ticks, big.NewInt(1))
+
+		slot0 := NewSlot0()
+		slot0.TickCurrent.SetInt64(c.tickCurrent)
+		slot0.FeeGrowthGlobal0X128.SetInt64(15)
+		slot0.FeeGrowthGlobal1X128.SetInt64(15)
+
+		inside0, inside1 := ts.GetFeeGrowthInside(big.NewInt(-2), big.NewInt(2), slot0)
+
+		if inside0.Cmp(c.ref0) != 0 || inside1.Cmp(c.ref1) != 0 {
+			t.Errorf("GetFeeGrowthInside() #%d = %d, %d; want %d, %d", i, inside0, inside1, c.ref0, c.ref1)
+		}
+	}
 }